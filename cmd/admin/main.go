@@ -0,0 +1,178 @@
+// Command ecommerce-admin manages admin accounts without the HTTP server.
+//
+//	ecommerce-admin create-admin --email a@b.c [--password ...]
+//	ecommerce-admin reset-password --email a@b.c [--password ...]
+//	ecommerce-admin list-admins
+//
+// When --password is omitted it is read from the first line of stdin,
+// so it can be piped in from a container secret.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"ecommerce/internal/auth"
+	"ecommerce/internal/config"
+	"ecommerce/internal/db"
+	"ecommerce/internal/domain/role"
+)
+
+func main() {
+	log.SetFlags(0)
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	if err := auth.ConfigurePasswordHashing(cfg); err != nil {
+		log.Fatal(err)
+	}
+	gormDB, err := db.NewPostgres(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sqlDB, _ := gormDB.DB()
+	defer sqlDB.Close()
+
+	users := auth.NewUserRepo(gormDB)
+	refresh := auth.NewRefreshRepo(gormDB)
+	policy, err := auth.NewPasswordPolicy(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "create-admin":
+		err = createAdmin(users, policy, args)
+	case "reset-password":
+		err = resetPassword(users, refresh, policy, args)
+	case "list-admins":
+		err = listAdmins(users)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ecommerce-admin <create-admin|reset-password|list-admins> [flags]")
+}
+
+// createAdmin creates a verified admin, or promotes an existing user
+func createAdmin(users *auth.UserRepo, policy *auth.PasswordPolicy, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := fs.String("email", "", "admin email (required)")
+	password := fs.String("password", "", "password for a new account (default: read from stdin)")
+	_ = fs.Parse(args)
+
+	addr := normalizeEmail(*email)
+	if addr == "" {
+		return errors.New("--email is required")
+	}
+
+	if u, err := users.ByEmail(addr); err == nil {
+		if err := users.SetRole(u.ID, role.Admin); err != nil {
+			return err
+		}
+		if !u.EmailVerified {
+			_ = users.SetEmailVerified(u.ID)
+		}
+		fmt.Printf("promoted existing user %d (%s) to admin\n", u.ID, u.Email)
+		return nil
+	}
+
+	pw, err := passwordArg(*password, policy, addr)
+	if err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(pw)
+	if err != nil {
+		return err
+	}
+	u, err := users.Create(addr, hash, role.Admin)
+	if err != nil {
+		return err
+	}
+	if err := users.SetEmailVerified(u.ID); err != nil {
+		return err
+	}
+	fmt.Printf("created admin %d (%s)\n", u.ID, u.Email)
+	return nil
+}
+
+// resetPassword sets a new password and ends all sessions of the user
+func resetPassword(users *auth.UserRepo, refresh *auth.RefreshRepo, policy *auth.PasswordPolicy, args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	email := fs.String("email", "", "account email (required)")
+	password := fs.String("password", "", "new password (default: read from stdin)")
+	_ = fs.Parse(args)
+
+	addr := normalizeEmail(*email)
+	if addr == "" {
+		return errors.New("--email is required")
+	}
+	u, err := users.ByEmail(addr)
+	if err != nil {
+		return fmt.Errorf("user %s not found", addr)
+	}
+
+	pw, err := passwordArg(*password, policy, u.Email)
+	if err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(pw)
+	if err != nil {
+		return err
+	}
+	if err := users.UpdatePassword(u.ID, hash); err != nil {
+		return err
+	}
+	_ = refresh.RevokeAllForUser(u.ID)
+	fmt.Printf("password reset for %d (%s)\n", u.ID, u.Email)
+	return nil
+}
+
+func listAdmins(users *auth.UserRepo) error {
+	admins, err := users.ListByRole(role.Admin)
+	if err != nil {
+		return err
+	}
+	for _, u := range admins {
+		fmt.Printf("%d\t%s\tactive=%t\t2fa=%t\n", u.ID, u.Email, u.IsActive, u.TOTPEnabled)
+	}
+	return nil
+}
+
+func passwordArg(flagValue string, policy *auth.PasswordPolicy, email string) (string, error) {
+	pw := flagValue
+	if pw == "" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("no password given (use --password or pipe it on stdin)")
+		}
+		pw = strings.TrimRight(line, "\r\n")
+	}
+	if violations := policy.Validate(pw, email); len(violations) > 0 {
+		return "", fmt.Errorf("password rejected: %s", strings.Join(violations, "; "))
+	}
+	return pw, nil
+}
+
+func normalizeEmail(s string) string {
+	return strings.TrimSpace(strings.ToLower(s))
+}
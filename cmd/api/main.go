@@ -2,23 +2,42 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
+	"ecommerce/internal/address"
+	"ecommerce/internal/audit"
 	"ecommerce/internal/auth"
 	"ecommerce/internal/cart"
 	"ecommerce/internal/categories"
 	"ecommerce/internal/config"
+	"ecommerce/internal/coupons"
+	"ecommerce/internal/credit"
 	"ecommerce/internal/db"
+	"ecommerce/internal/domain/role"
+	"ecommerce/internal/export"
 	"ecommerce/internal/mail"
+	"ecommerce/internal/oidc"
+	"ecommerce/internal/pricing"
 	"ecommerce/internal/products"
+	"ecommerce/internal/promotions"
+	"ecommerce/internal/ratelimit"
+	"ecommerce/internal/restock"
+	"ecommerce/internal/shipments"
+	"ecommerce/internal/shipping"
+	"ecommerce/internal/tax"
+	"ecommerce/internal/wishlist"
 )
 
 func main() {
 	_ = godotenv.Load()
 
 	cfg := config.Load()
+	if err := auth.ConfigurePasswordHashing(cfg); err != nil {
+		log.Fatal(err)
+	}
 
 	gormDB, err := db.NewPostgres(cfg.DatabaseURL)
 	if err != nil {
@@ -35,12 +54,31 @@ func main() {
 		From: cfg.SMTPFrom,
 	})
 
+	accessKeys, err := auth.ParseSigningKeys(cfg.JWTSigningKeys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var hs256AcceptUntil time.Time
+	if cfg.JWTHS256AcceptUntil != "" {
+		if hs256AcceptUntil, err = time.Parse(time.RFC3339, cfg.JWTHS256AcceptUntil); err != nil {
+			log.Fatalf("JWT_HS256_ACCEPT_UNTIL: %v", err)
+		}
+	}
+
+	passwordPolicy, err := auth.NewPasswordPolicy(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	jwtMgr := auth.NewJWTManager(auth.JWTConfig{
 		Issuer:         cfg.JWTIssuer,
 		AccessSecret:   cfg.JWTAccessSecret,
 		RefreshSecret:  cfg.JWTRefreshSecret,
 		AccessTTLMin:   cfg.AccessTokenTTLMin,
 		RefreshTTLDays: cfg.RefreshTokenTTLDays,
+		AccessKeys:     accessKeys,
+
+		HS256AcceptUntil: hs256AcceptUntil,
 	})
 
 	// Repos (all using GORM now)
@@ -48,30 +86,125 @@ func main() {
 	refreshRepo := auth.NewRefreshRepo(gormDB)
 	resetRepo := auth.NewResetRepo(gormDB)
 	otpRepo := auth.NewOTPRepo(gormDB)
+	recoveryRepo := auth.NewRecoveryRepo(gormDB)
+	identityRepo := auth.NewIdentityRepo(gormDB)
+	throttleRepo := auth.NewThrottleRepo(gormDB)
+	deviceRepo := auth.NewDeviceRepo(gormDB)
+	roleRepo := auth.NewRoleRepo(gormDB)
+	auditRepo := audit.NewRepo(gormDB)
+
+	// OpenID Connect providers ("Sign in with ...")
+	oidcClients := map[string]*oidc.Client{}
+	for _, p := range cfg.OIDCProviders {
+		oidcClients[p.Name] = oidc.NewClient(oidc.ProviderConfig{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  cfg.AppBaseURL + "/api/auth/oidc/" + p.Name + "/callback",
+			Scopes:       p.Scopes,
+		}, nil)
+	}
+
+	cartRepo := cart.NewRepo(gormDB)
+	taxRepo := tax.NewRepo(gormDB)
+	taxHandler := tax.NewHandler(taxRepo)
+	cartHandler := cart.NewHandler(cartRepo, cart.Config{
+		SecureCookie:     cfg.AppEnv != "dev",
+		Tax:              tax.NewRateCalculator(taxRepo),
+		PricesIncludeTax: cfg.PricesIncludeTax,
+	})
 
 	// Handler with OTP dependency
 	h := auth.NewHandler(auth.Dependencies{
-		Cfg:     cfg,
-		JWT:     jwtMgr,
-		Users:   userRepo,
-		Refresh: refreshRepo,
-		Resets:  resetRepo,
-		OTP:     otpRepo,
-		Mailer:  mailer,
+		Cfg:      cfg,
+		JWT:      jwtMgr,
+		Users:    userRepo,
+		Refresh:  refreshRepo,
+		Resets:   resetRepo,
+		OTP:      otpRepo,
+		Recovery: recoveryRepo,
+		Mailer:   mailer,
+
+		Identities: identityRepo,
+		OIDC:       oidcClients,
+
+		Throttle: throttleRepo,
+		Devices:  deviceRepo,
+
+		Roles: roleRepo,
+		Audit: auditRepo,
+
+		Passwords: passwordPolicy,
+
+		Carts: cartHandler,
 	})
 
+	auditHandler := audit.NewHandler(auditRepo)
+
 	// Catalog repos/handlers (GORM)
 	catRepo := categories.NewRepo(gormDB)
 	catHandler := categories.NewHandler(catRepo)
 
+	couponRepo := coupons.NewRepo(gormDB)
+	couponHandler := coupons.NewHandler(couponRepo)
+
+	priceRuleRepo := pricing.NewRepo(gormDB)
+	priceRuleHandler := pricing.NewHandler(priceRuleRepo)
+
+	promotionRepo := promotions.NewRepo(gormDB)
+	promotionHandler := promotions.NewHandler(promotionRepo)
+
+	restockRepo := restock.NewRepo(gormDB)
+	restockHandler := restock.NewHandler(restockRepo, mailer, restock.Config{
+		SigningSecret: cfg.DownloadSigningSecret,
+		BaseURL:       cfg.AppBaseURL,
+	})
+	restockNotifier := restock.NewNotifier(restockRepo, mailer, cfg.AppBaseURL)
+
 	prodRepo := products.NewRepo(gormDB)
-	prodHandler := products.NewHandler(prodRepo)
+	prodHandler := products.NewHandler(prodRepo, restockNotifier)
 
-	cartRepo := cart.NewRepo(gormDB)
-	cartHandler := cart.NewHandler(cartRepo)
+	wishlistRepo := wishlist.NewRepo(gormDB)
+	wishlistHandler := wishlist.NewHandler(wishlistRepo, cartRepo)
+
+	shippingRepo := shipping.NewRepo(gormDB)
+	shippingHandler := shipping.NewHandler(shippingRepo)
+
+	// Carrier adapters: the defaults give tracking links only, so their
+	// events are entered by hand until an API adapter is registered here
+	carriers := shipments.NewCarriers(shipments.DefaultCarriers()...)
+	shipmentRepo := shipments.NewRepo(gormDB)
+	shipmentNotifier := shipments.NewNotifier(shipmentRepo, mailer, carriers, cfg.AppBaseURL)
+	shipmentHandler := shipments.NewHandler(shipmentRepo, carriers, shipmentNotifier)
+
+	addressRepo := address.NewRepo(gormDB)
+	addressHandler := address.NewHandler(addressRepo)
+
+	creditRepo := credit.NewRepo(gormDB)
+	creditHandler := credit.NewHandler(creditRepo, auditRepo)
+
+	exportRepo := export.NewRepo(gormDB)
+	exportHandler := export.NewHandler(exportRepo, mailer, export.Config{
+		Dir:           cfg.ExportDir,
+		LinkTTL:       time.Duration(cfg.ExportLinkTTLMin) * time.Minute,
+		BuildTimeout:  time.Duration(cfg.ExportBuildTimeoutMin) * time.Minute,
+		SigningSecret: cfg.DownloadSigningSecret,
+		BaseURL:       cfg.AppBaseURL,
+	})
+	exportHandler.StartCleanup(10 * time.Minute)
 
 	r := gin.Default()
 
+	// Lockouts and new-device alerts key on the client IP, so only listed
+	// proxies may set it via X-Forwarded-For
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal(err)
+	}
+
+	// Public keys for services verifying our access tokens
+	r.GET("/.well-known/jwks.json", h.JWKS)
+
 	// Auth routes
 	api := r.Group("/api")
 	authGroup := api.Group("/auth")
@@ -83,6 +216,16 @@ func main() {
 
 		// Login / Refresh / Logout
 		authGroup.POST("/login", h.Login)
+		authGroup.POST("/login/mfa", h.LoginMFA)
+
+		// Passwordless login via email OTP
+		authGroup.POST("/otp/request", h.RequestLoginOTP)
+		authGroup.POST("/otp/verify", h.VerifyLoginOTP)
+
+		// Social login (OpenID Connect, authorization code + PKCE)
+		authGroup.GET("/oidc/:provider/start", h.OIDCStart)
+		authGroup.GET("/oidc/:provider/callback", h.OIDCCallback)
+		authGroup.POST("/oidc/link", h.OIDCLink)
 		authGroup.POST("/refresh", h.Refresh)
 		authGroup.POST("/logout", h.Logout)
 
@@ -96,32 +239,187 @@ func main() {
 	api.GET("/products", prodHandler.ListPublic)
 	api.GET("/products/:id", prodHandler.GetPublic)
 
+	// Back-in-stock email (guests send an email and confirm it, users use
+	// their account email)
+	api.POST("/variants/:id/notify-me", ratelimit.PerIP(5, time.Minute), auth.OptionalAuth(jwtMgr), restockHandler.NotifyMe)
+	api.GET("/stock-notifications/:id/confirm", restockHandler.Confirm)
+
+	// Shared wishlists (public read-only link)
+	api.GET("/shared/wishlists/:token", wishlistHandler.GetShared)
+
+	// Gift card balance check by code
+	api.GET("/gift-cards/balance", ratelimit.PerIP(10, time.Minute), creditHandler.GiftCardBalance)
+
+	// Signed data export download link (sent by email)
+	api.GET("/exports/:id/download", exportHandler.Download)
+
+	// Cart (logged-in users, or guests identified by a cart token)
+	cartGroup := api.Group("/cart")
+	cartGroup.Use(auth.OptionalAuth(jwtMgr))
+	{
+		cartGroup.GET("", cartHandler.GetMyCart)
+		cartGroup.POST("/items", cartHandler.AddItem)
+		cartGroup.PATCH("/items", cartHandler.UpdateQty)
+		cartGroup.DELETE("/items", cartHandler.RemoveItem)
+		cartGroup.POST("/coupon", ratelimit.PerIP(10, time.Minute), cartHandler.ApplyCoupon)
+		cartGroup.DELETE("/coupon", cartHandler.RemoveCoupon)
+		cartGroup.POST("/shipping-quote", cartHandler.ShippingQuote)
+	}
+
 	// Protected routes
 	protected := api.Group("/")
 	protected.Use(auth.AuthMiddleware(jwtMgr))
 	{
 		protected.GET("/me", h.Me)
+		protected.PATCH("/me", h.UpdateMe)
+		protected.DELETE("/me", h.DeleteMe)
+		protected.POST("/me/reauth", h.RequestReauth)
+		protected.POST("/me/password", h.ChangePassword)
+		protected.POST("/me/email", h.RequestEmailChange)
+		protected.POST("/me/email/confirm", h.ConfirmEmailChange)
+
+		// Personal data export (GDPR)
+		protected.POST("/me/export", exportHandler.Request)
+		protected.GET("/me/exports", exportHandler.List)
+
+		// TOTP two-factor authentication
+		protected.POST("/me/2fa/setup", h.Setup2FA)
+		protected.POST("/me/2fa/enable", h.Enable2FA)
+		protected.POST("/me/2fa/disable", h.Disable2FA)
+		protected.POST("/me/2fa/recovery-codes", h.RegenerateRecoveryCodes)
+
+		// Wishlists and saved-for-later
+		protected.GET("/wishlists", wishlistHandler.List)
+		protected.POST("/wishlists", wishlistHandler.Create)
+		protected.GET("/wishlists/:id", wishlistHandler.Get)
+		protected.DELETE("/wishlists/:id", wishlistHandler.Delete)
+		protected.POST("/wishlists/:id/items", wishlistHandler.AddItem)
+		protected.DELETE("/wishlists/:id/items/:item_id", wishlistHandler.RemoveItem)
+		protected.POST("/wishlists/:id/items/:item_id/move-to-cart", wishlistHandler.MoveToCart)
+		protected.POST("/wishlists/:id/share", wishlistHandler.Share)
+		protected.DELETE("/wishlists/:id/share", wishlistHandler.Unshare)
+		protected.POST("/cart/save-for-later", wishlistHandler.SaveForLater)
 
-		// Cart (user must login)
-		protected.GET("/cart", cartHandler.GetMyCart)
-		protected.POST("/cart/items", cartHandler.AddItem)
-		protected.PATCH("/cart/items", cartHandler.UpdateQty)
-		protected.DELETE("/cart/items", cartHandler.RemoveItem)
+		// Address book
+		protected.GET("/me/addresses", addressHandler.List)
+		protected.POST("/me/addresses", addressHandler.Create)
+		protected.GET("/me/addresses/:id", addressHandler.Get)
+		protected.PUT("/me/addresses/:id", addressHandler.Update)
+		protected.DELETE("/me/addresses/:id", addressHandler.Delete)
 
+		// Shipment tracking
+		protected.GET("/me/shipments", shipmentHandler.MyShipments)
+		protected.GET("/me/shipments/:id", shipmentHandler.MyShipment)
+
+		// Store credit wallet (gift cards are redeemed into it)
+		protected.GET("/me/store-credit", creditHandler.StoreCredit)
+		protected.POST("/me/gift-cards/redeem", creditHandler.RedeemGiftCard)
+
+		// Staff area: every route needs a specific permission, and staff
+		// must have logged in with a second factor
 		adminOnly := protected.Group("/admin")
-		adminOnly.Use(auth.RequireRole("admin"))
+		adminOnly.Use(auth.RequirePermission(role.PermAdminAccess), auth.RequireMFA())
 
 		adminOnly.GET("/dashboard", func(c *gin.Context) {
 			c.JSON(200, gin.H{"ok": true, "message": "admin access granted"})
 		})
 
+		// Login lockouts
+		adminOnly.GET("/locked-accounts", auth.RequirePermission(role.PermSecurityManage), h.AdminListLocked)
+		adminOnly.POST("/locked-accounts/:id/unlock", auth.RequirePermission(role.PermSecurityManage), h.AdminUnlock)
+
+		// User management
+		adminOnly.GET("/users", auth.RequirePermission(role.PermUsersRead), h.AdminListUsers)
+		adminOnly.GET("/users/:id", auth.RequirePermission(role.PermUsersRead), h.AdminGetUser)
+		adminOnly.PATCH("/users/:id", auth.RequirePermission(role.PermUsersWrite), h.AdminUpdateUser)
+		adminOnly.POST("/users/:id/password-reset", auth.RequirePermission(role.PermUsersWrite), h.AdminTriggerPasswordReset)
+		adminOnly.GET("/audit-log", auth.RequirePermission(role.PermAuditRead), auditHandler.AdminList)
+
+		// Roles and permissions
+		rolesAdmin := adminOnly.Group("/")
+		rolesAdmin.Use(auth.RequirePermission(role.PermRolesManage))
+		rolesAdmin.GET("/roles", h.AdminListRoles)
+		rolesAdmin.POST("/roles", h.AdminCreateRole)
+		rolesAdmin.PUT("/roles/:name/permissions", h.AdminSetRolePermissions)
+		rolesAdmin.DELETE("/roles/:name", h.AdminDeleteRole)
+		rolesAdmin.GET("/permissions", h.AdminListPermissions)
+		rolesAdmin.PUT("/users/:id/role", h.AdminAssignRole)
+
 		// Admin category CRUD
 		adminOnly.GET("/categories", catHandler.AdminList)
-		adminOnly.POST("/categories", catHandler.AdminCreate)
-		adminOnly.PATCH("/categories/:id", catHandler.AdminUpdate)
+		adminOnly.POST("/categories", auth.RequirePermission(role.PermCategoriesWrite), catHandler.AdminCreate)
+		adminOnly.PATCH("/categories/:id", auth.RequirePermission(role.PermCategoriesWrite), catHandler.AdminUpdate)
 
 		// Admin add product
-		adminOnly.POST("/products", prodHandler.AdminCreate)
+		adminOnly.POST("/products", auth.RequirePermission(role.PermProductsWrite), prodHandler.AdminCreate)
+
+		// Coupons, scheduled sales, bundles and quantity tiers
+		promotionsAdmin := adminOnly.Group("/")
+		promotionsAdmin.Use(auth.RequirePermission(role.PermPromotionsWrite))
+		promotionsAdmin.GET("/coupons", couponHandler.AdminList)
+		promotionsAdmin.POST("/coupons", couponHandler.AdminCreate)
+		promotionsAdmin.GET("/coupons/:id", couponHandler.AdminGet)
+		promotionsAdmin.PUT("/coupons/:id", couponHandler.AdminUpdate)
+		promotionsAdmin.DELETE("/coupons/:id", couponHandler.AdminDelete)
+		promotionsAdmin.GET("/price-rules", priceRuleHandler.AdminList)
+		promotionsAdmin.POST("/price-rules", priceRuleHandler.AdminCreate)
+		promotionsAdmin.GET("/price-rules/:id", priceRuleHandler.AdminGet)
+		promotionsAdmin.PUT("/price-rules/:id", priceRuleHandler.AdminUpdate)
+		promotionsAdmin.DELETE("/price-rules/:id", priceRuleHandler.AdminDelete)
+		promotionsAdmin.GET("/promotions", promotionHandler.AdminList)
+		promotionsAdmin.POST("/promotions", promotionHandler.AdminCreate)
+		promotionsAdmin.GET("/promotions/:id", promotionHandler.AdminGet)
+		promotionsAdmin.PUT("/promotions/:id", promotionHandler.AdminUpdate)
+		promotionsAdmin.DELETE("/promotions/:id", promotionHandler.AdminDelete)
+
+		// Gift cards and store credit
+		creditAdmin := adminOnly.Group("/")
+		creditAdmin.Use(auth.RequirePermission(role.PermCreditManage))
+		creditAdmin.GET("/gift-cards", creditHandler.AdminListGiftCards)
+		creditAdmin.POST("/gift-cards", creditHandler.AdminIssueGiftCard)
+		creditAdmin.GET("/gift-cards/:id", creditHandler.AdminGetGiftCard)
+		creditAdmin.POST("/gift-cards/:id/adjust", creditHandler.AdminAdjustGiftCard)
+		creditAdmin.PATCH("/gift-cards/:id", creditHandler.AdminSetGiftCardActive)
+		creditAdmin.GET("/users/:id/store-credit", creditHandler.AdminUserStoreCredit)
+		creditAdmin.POST("/users/:id/store-credit", creditHandler.AdminChangeUserStoreCredit)
+
+		// Inventory (restocking a sold-out variant emails its subscribers)
+		adminOnly.PATCH("/variants/:id/stock", auth.RequirePermission(role.PermInventoryWrite), prodHandler.AdminUpdateStock)
+		adminOnly.PATCH("/variants/:id/weight", auth.RequirePermission(role.PermProductsWrite), prodHandler.AdminUpdateWeight)
+
+		// Fulfillment: shipments and tracking updates
+		fulfillmentAdmin := adminOnly.Group("/shipments")
+		fulfillmentAdmin.Use(auth.RequirePermission(role.PermFulfillment))
+		fulfillmentAdmin.GET("", shipmentHandler.AdminList)
+		fulfillmentAdmin.POST("", shipmentHandler.AdminCreate)
+		fulfillmentAdmin.GET("/:id", shipmentHandler.AdminGet)
+		fulfillmentAdmin.PATCH("/:id/tracking", shipmentHandler.AdminSetTracking)
+		fulfillmentAdmin.POST("/:id/events", shipmentHandler.AdminAddEvent)
+		fulfillmentAdmin.POST("/:id/sync", shipmentHandler.AdminSync)
+
+		// Tax rates and product tax classes
+		taxAdmin := adminOnly.Group("/")
+		taxAdmin.Use(auth.RequirePermission(role.PermTaxManage))
+		taxAdmin.GET("/tax-rates", taxHandler.AdminList)
+		taxAdmin.POST("/tax-rates", taxHandler.AdminCreate)
+		taxAdmin.GET("/tax-rates/:id", taxHandler.AdminGet)
+		taxAdmin.PUT("/tax-rates/:id", taxHandler.AdminUpdate)
+		taxAdmin.DELETE("/tax-rates/:id", taxHandler.AdminDelete)
+		taxAdmin.PATCH("/products/:id/tax-class", taxHandler.AdminSetProductClass)
+
+		// Shipping zones, methods and rate tables
+		shippingAdmin := adminOnly.Group("/shipping")
+		shippingAdmin.Use(auth.RequirePermission(role.PermShippingManage))
+		shippingAdmin.GET("/zones", shippingHandler.AdminListZones)
+		shippingAdmin.POST("/zones", shippingHandler.AdminCreateZone)
+		shippingAdmin.GET("/zones/:id", shippingHandler.AdminGetZone)
+		shippingAdmin.PUT("/zones/:id", shippingHandler.AdminUpdateZone)
+		shippingAdmin.DELETE("/zones/:id", shippingHandler.AdminDeleteZone)
+		shippingAdmin.GET("/methods", shippingHandler.AdminListMethods)
+		shippingAdmin.POST("/methods", shippingHandler.AdminCreateMethod)
+		shippingAdmin.GET("/methods/:id", shippingHandler.AdminGetMethod)
+		shippingAdmin.PUT("/methods/:id", shippingHandler.AdminUpdateMethod)
+		shippingAdmin.DELETE("/methods/:id", shippingHandler.AdminDeleteMethod)
 	}
 
 	log.Printf("listening on %s", cfg.HTTPAddr)
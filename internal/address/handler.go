@@ -0,0 +1,158 @@
+package address
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/auth"
+	addressDomain "ecommerce/internal/domain/address"
+)
+
+type Handler struct {
+	repo *Repo
+}
+
+func NewHandler(repo *Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+var (
+	countryRe = regexp.MustCompile(`^[A-Z]{2}$`)
+	phoneRe   = regexp.MustCompile(`^\+?[0-9 ()-]{6,20}$`)
+)
+
+type AddressReq struct {
+	Label             string `json:"label"`
+	FullName          string `json:"full_name" binding:"required"`
+	Company           string `json:"company"`
+	Line1             string `json:"line1" binding:"required"`
+	Line2             string `json:"line2"`
+	City              string `json:"city" binding:"required"`
+	Region            string `json:"region"`
+	PostalCode        string `json:"postal_code"`
+	Country           string `json:"country" binding:"required"`
+	Phone             string `json:"phone"`
+	IsDefaultShipping bool   `json:"is_default_shipping"`
+	IsDefaultBilling  bool   `json:"is_default_billing"`
+}
+
+// toAddress validates the request and builds the address
+func (req AddressReq) toAddress() (addressDomain.Address, string) {
+	a := addressDomain.Address{
+		Label:             strings.TrimSpace(req.Label),
+		FullName:          strings.TrimSpace(req.FullName),
+		Company:           strings.TrimSpace(req.Company),
+		Line1:             strings.TrimSpace(req.Line1),
+		Line2:             strings.TrimSpace(req.Line2),
+		City:              strings.TrimSpace(req.City),
+		Region:            strings.ToUpper(strings.TrimSpace(req.Region)),
+		Country:           strings.ToUpper(strings.TrimSpace(req.Country)),
+		Phone:             strings.TrimSpace(req.Phone),
+		IsDefaultShipping: req.IsDefaultShipping,
+		IsDefaultBilling:  req.IsDefaultBilling,
+	}
+	switch {
+	case a.FullName == "" || a.Line1 == "" || a.City == "":
+		return a, "full_name, line1 and city are required"
+	case len(a.Label) > 50 || len(a.FullName) > 200 || len(a.Company) > 200 ||
+		len(a.Line1) > 200 || len(a.Line2) > 200 || len(a.City) > 100 || len(a.Region) > 100:
+		return a, "address field too long"
+	case !countryRe.MatchString(a.Country):
+		return a, "country must be an ISO 3166-1 alpha-2 code"
+	case a.Phone != "" && !phoneRe.MatchString(a.Phone):
+		return a, "invalid phone number"
+	}
+	code, ok := NormalizePostalCode(a.Country, req.PostalCode)
+	if !ok {
+		return a, "invalid postal code for " + a.Country
+	}
+	a.PostalCode = code
+	return a, ""
+}
+
+func (h *Handler) List(c *gin.Context) {
+	items, err := h.repo.List(c.GetInt64(auth.CtxUserIDKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list addresses"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) Get(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	a, err := h.repo.Get(c.GetInt64(auth.CtxUserIDKey), id)
+	if err != nil {
+		respondError(c, err, "failed to load address")
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	var req AddressReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	a, msg := req.toAddress()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	a.UserID = c.GetInt64(auth.CtxUserIDKey)
+	if err := h.repo.Create(&a); err != nil {
+		respondError(c, err, "failed to create address")
+		return
+	}
+	c.JSON(http.StatusCreated, a)
+}
+
+// Update replaces the address (PUT semantics)
+func (h *Handler) Update(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	var req AddressReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	a, msg := req.toAddress()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	a.ID, a.UserID = id, userID
+	if err := h.repo.Update(&a); err != nil {
+		respondError(c, err, "failed to update address")
+		return
+	}
+	a, _ = h.repo.Get(userID, id)
+	c.JSON(http.StatusOK, a)
+}
+
+func (h *Handler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.repo.Delete(c.GetInt64(auth.CtxUserIDKey), id); err != nil {
+		respondError(c, err, "failed to delete address")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTooMany):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
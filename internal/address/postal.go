@@ -0,0 +1,87 @@
+package address
+
+import (
+	"regexp"
+	"strings"
+)
+
+// postalFormats holds the postal code pattern for countries we validate
+// strictly, matched against the normalized (uppercased, single-spaced) code.
+var postalFormats = map[string]*regexp.Regexp{
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] \d[ABCEGHJ-NPRSTV-Z]\d$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? \d[A-Z]{2}$`),
+	"IE": regexp.MustCompile(`^([AC-FHKNPRTV-Y]\d{2}|D6W) [AC-FHKNPRTV-Y\d]{4}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"NL": regexp.MustCompile(`^\d{4} [A-Z]{2}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"SE": regexp.MustCompile(`^\d{3} \d{2}$`),
+	"FI": regexp.MustCompile(`^\d{5}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"NZ": regexp.MustCompile(`^\d{4}$`),
+	"JP": regexp.MustCompile(`^\d{3}-\d{4}$`),
+	"IN": regexp.MustCompile(`^\d{6}$`),
+	"BR": regexp.MustCompile(`^\d{5}-\d{3}$`),
+	"MX": regexp.MustCompile(`^\d{5}$`),
+}
+
+// noPostalCode lists countries that do not use postal codes; anything the
+// customer typed is dropped
+var noPostalCode = map[string]bool{
+	"AE": true, "HK": true, "QA": true, "AO": true, "BS": true, "FJ": true,
+}
+
+// inwardLen is the length of the part after the space for formats with one,
+// counted from the end since the leading part varies in length
+var inwardLen = map[string]int{"GB": 3, "CA": 3, "IE": 4, "NL": 2, "SE": 2}
+
+var genericPostal = regexp.MustCompile(`^[A-Z\d][A-Z\d -]{1,9}$`)
+
+// NormalizePostalCode uppercases and tidies a postal code and checks it
+// against the country's format. Some formats get their separator added
+// when the customer left it out (e.g. "SW1A1AA" -> "SW1A 1AA"). The code
+// is only required for countries with a known format; many others (much of
+// Africa and the Gulf) have none.
+func NormalizePostalCode(country, code string) (string, bool) {
+	code = strings.Join(strings.Fields(strings.ToUpper(code)), " ")
+	if noPostalCode[country] {
+		return "", true
+	}
+	if code == "" {
+		_, required := postalFormats[country]
+		return "", !required
+	}
+
+	if n, ok := inwardLen[country]; ok {
+		compact := strings.ReplaceAll(code, " ", "")
+		if len(compact) > n {
+			code = compact[:len(compact)-n] + " " + compact[len(compact)-n:]
+		}
+	}
+	if country == "US" && len(code) == 9 && isDigits(code) {
+		code = code[:5] + "-" + code[5:]
+	}
+
+	if re, ok := postalFormats[country]; ok {
+		return code, re.MatchString(code)
+	}
+	return code, genericPostal.MatchString(code)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
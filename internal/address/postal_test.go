@@ -0,0 +1,52 @@
+package address
+
+import "testing"
+
+func TestNormalizePostalCode(t *testing.T) {
+	tests := []struct {
+		country, code string
+		want          string
+		ok            bool
+	}{
+		{"US", "94105", "94105", true},
+		{"US", "94105-1234", "94105-1234", true},
+		{"US", "941051234", "94105-1234", true},
+		{"US", "9410", "9410", false},
+		{"CA", "k1a0b1", "K1A 0B1", true},
+		{"CA", "D1A 0B1", "D1A 0B1", false},
+		{"GB", "sw1a1aa", "SW1A 1AA", true},
+		{"GB", "M1 1AE", "M1 1AE", true},
+		{"GB", "SW1A 1A", "SW1 A1A", false},
+		{"IE", "d02x285", "D02 X285", true},
+		{"IE", "D6W 1234", "D6W 1234", true},
+		{"NL", "1234ab", "1234 AB", true},
+		{"SE", "11455", "114 55", true},
+		{"DE", " 10115 ", "10115", true},
+		{"DE", "1011", "1011", false},
+		{"PL", "00-950", "00-950", true},
+		{"PT", "1000-001", "1000-001", true},
+		{"JP", "100-0001", "100-0001", true},
+		{"BR", "01310-100", "01310-100", true},
+		{"IN", "110001", "110001", true},
+
+		// required where the format is known
+		{"DE", "", "", false},
+		{"US", "  ", "", false},
+
+		// countries without postal codes drop whatever was typed
+		{"AE", "12345", "", true},
+		{"HK", "", "", true},
+
+		// other countries: optional, loosely checked when given
+		{"NG", "", "", true},
+		{"KE", "00100", "00100", true},
+		{"SA", "12211 4567", "12211 4567", true},
+		{"ZA", "#1", "#1", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizePostalCode(tt.country, tt.code)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizePostalCode(%q, %q) = %q, %v; want %q, %v", tt.country, tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+}
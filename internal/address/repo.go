@@ -0,0 +1,150 @@
+package address
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	addressDomain "ecommerce/internal/domain/address"
+)
+
+var (
+	ErrNotFound = errors.New("address not found")
+	ErrTooMany  = errors.New("address book is full")
+)
+
+const MaxPerUser = 50
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// List returns the user's addresses, defaults first
+func (r *Repo) List(userID int64) ([]addressDomain.Address, error) {
+	var out []addressDomain.Address
+	err := r.db.Where("user_id = ?", userID).
+		Order("is_default_shipping DESC, is_default_billing DESC, created_at DESC").
+		Find(&out).Error
+	return out, err
+}
+
+// Get loads one of the user's addresses. Shipping quotes and checkout use
+// it to resolve an address id, so another user's id is simply not found.
+func (r *Repo) Get(userID, id int64) (addressDomain.Address, error) {
+	var a addressDomain.Address
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return a, ErrNotFound
+	}
+	return a, err
+}
+
+// DefaultShipping returns the user's default shipping address
+func (r *Repo) DefaultShipping(userID int64) (addressDomain.Address, error) {
+	var a addressDomain.Address
+	err := r.db.Where("user_id = ? AND is_default_shipping", userID).First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return a, ErrNotFound
+	}
+	return a, err
+}
+
+// Create stores the address; the user's first address becomes the default
+// for both shipping and billing.
+func (r *Repo) Create(a *addressDomain.Address) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&addressDomain.Address{}).Where("user_id = ?", a.UserID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n >= MaxPerUser {
+			return ErrTooMany
+		}
+		if n == 0 {
+			a.IsDefaultShipping, a.IsDefaultBilling = true, true
+		}
+		if err := clearDefaults(tx, *a); err != nil {
+			return err
+		}
+		return tx.Create(a).Error
+	})
+}
+
+// Update replaces the address (PUT semantics). Unsetting a default leaves
+// the user without one until another address is marked.
+func (r *Repo) Update(a *addressDomain.Address) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaults(tx, *a); err != nil {
+			return err
+		}
+		res := tx.Model(&addressDomain.Address{}).
+			Where("id = ? AND user_id = ?", a.ID, a.UserID).
+			Select("label", "full_name", "company", "line1", "line2", "city", "region",
+				"postal_code", "country", "phone", "is_default_shipping", "is_default_billing").
+			Updates(a)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// Delete removes the address; a default it held passes to the most
+// recently created remaining address.
+func (r *Repo) Delete(userID, id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var a addressDomain.Address
+		err := tx.Where("id = ? AND user_id = ?", id, userID).First(&a).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&a).Error; err != nil {
+			return err
+		}
+
+		for col, was := range map[string]bool{
+			"is_default_shipping": a.IsDefaultShipping,
+			"is_default_billing":  a.IsDefaultBilling,
+		} {
+			if !was {
+				continue
+			}
+			err := tx.Exec(`
+				UPDATE addresses SET `+col+` = TRUE
+				WHERE id = (SELECT id FROM addresses WHERE user_id = ? ORDER BY created_at DESC, id DESC LIMIT 1)`,
+				userID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// clearDefaults unsets the flags a is about to take from the user's other
+// addresses (one default of each kind per user).
+func clearDefaults(tx *gorm.DB, a addressDomain.Address) error {
+	q := tx.Model(&addressDomain.Address{}).Where("user_id = ? AND id <> ?", a.UserID, a.ID).
+		Session(&gorm.Session{})
+	if a.IsDefaultShipping {
+		if err := q.Update("is_default_shipping", false).Error; err != nil {
+			return err
+		}
+	}
+	if a.IsDefaultBilling {
+		if err := q.Update("is_default_billing", false).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+
+	"ecommerce/internal/domain/audit"
+)
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// Record appends an entry; details is marshalled to JSON (nil allowed)
+func (r *Repo) Record(actorID *int64, action, targetType, targetID, ip string, details any) error {
+	e := audit.Entry{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         ip,
+	}
+	if details != nil {
+		b, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		e.Details = b
+	}
+	return r.db.Create(&e).Error
+}
+
+type ListFilter struct {
+	ActorID    *int64
+	TargetType string
+	TargetID   string
+	Limit      int
+	Offset     int
+}
+
+func (r *Repo) List(f ListFilter) ([]audit.Entry, int64, error) {
+	q := r.db.Model(&audit.Entry{})
+	if f.ActorID != nil {
+		q = q.Where("actor_id = ?", *f.ActorID)
+	}
+	if f.TargetType != "" {
+		q = q.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		q = q.Where("target_id = ?", f.TargetID)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var out []audit.Entry
+	err := q.Order("id DESC").Limit(f.Limit).Offset(f.Offset).Find(&out).Error
+	return out, total, err
+}
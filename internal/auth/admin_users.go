@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/audit"
+	"ecommerce/internal/domain/role"
+	"ecommerce/internal/domain/user"
+)
+
+type adminUpdateUserReq struct {
+	IsActive      *bool   `json:"is_active"`
+	Role          *string `json:"role"`
+	EmailVerified *bool   `json:"email_verified"`
+}
+
+// Admin: list users (?email= substring search, ?page=&page_size=)
+func (h *Handler) AdminListUsers(c *gin.Context) {
+	page, pageSize := audit.Pagination(c)
+	email := strings.TrimSpace(strings.ToLower(c.Query("email")))
+
+	users, total, err := h.deps.Users.List(email, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": sanitizeUsers(users), "total": total, "page": page, "page_size": pageSize})
+}
+
+func (h *Handler) AdminGetUser(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	u, err := h.deps.Users.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	c.JSON(http.StatusOK, sanitizeUser(u))
+}
+
+// Admin: activate/deactivate, change role, force email verification
+func (h *Handler) AdminUpdateUser(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req adminUpdateUserReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	u, err := h.deps.Users.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	actorID := c.GetInt64(CtxUserIDKey)
+	if actorID == u.ID && (req.IsActive != nil || req.Role != nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot change your own status or role"})
+		return
+	}
+
+	if req.Role != nil {
+		if !HasPermission(c, role.PermRolesManage) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		if _, err := h.deps.Roles.ByName(*req.Role); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown role"})
+			return
+		}
+	}
+
+	if req.IsActive != nil && *req.IsActive != u.IsActive {
+		if err := h.deps.Users.SetActive(u.ID, *req.IsActive); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+			return
+		}
+		action := "user.activate"
+		if !*req.IsActive {
+			action = "user.deactivate"
+			_ = h.deps.Refresh.RevokeAllForUser(u.ID)
+		}
+		h.audit(c, action, u.ID, nil)
+	}
+
+	if req.Role != nil && *req.Role != u.Role {
+		if err := h.deps.Users.SetRole(u.ID, *req.Role); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+			return
+		}
+		h.audit(c, "user.role_change", u.ID, gin.H{"from": u.Role, "to": *req.Role})
+	}
+
+	if req.EmailVerified != nil && *req.EmailVerified != u.EmailVerified {
+		if err := h.deps.Users.SetEmailVerifiedTo(u.ID, *req.EmailVerified); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+			return
+		}
+		h.audit(c, "user.email_verified", u.ID, gin.H{"value": *req.EmailVerified})
+	}
+
+	u, _ = h.deps.Users.ByID(u.ID)
+	c.JSON(http.StatusOK, sanitizeUser(u))
+}
+
+// Admin: send the user a password reset OTP (same email as forgot-password)
+func (h *Handler) AdminTriggerPasswordReset(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	u, err := h.deps.Users.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if !u.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user is deactivated"})
+		return
+	}
+
+	otp, exp, err := h.issueOTP(u.ID, OTPPurposeResetPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue otp"})
+		return
+	}
+	if err := h.sendOTPEmail(u.Email, otp, exp, "Reset password OTP"); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to send email"})
+		return
+	}
+	h.audit(c, "user.password_reset", u.ID, nil)
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// audit records an admin action on a user; failures are not fatal
+func (h *Handler) audit(c *gin.Context, action string, targetUserID int64, details any) {
+	if h.deps.Audit == nil {
+		return
+	}
+	actorID := c.GetInt64(CtxUserIDKey)
+	_ = h.deps.Audit.Record(&actorID, action, "user", strconv.FormatInt(targetUserID, 10), c.ClientIP(), details)
+}
+
+func sanitizeUsers(us []user.User) []gin.H {
+	out := make([]gin.H, 0, len(us))
+	for _, u := range us {
+		out = append(out, sanitizeUser(u))
+	}
+	return out
+}
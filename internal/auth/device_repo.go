@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"ecommerce/internal/util"
+)
+
+// KnownDevice is the GORM model for user_known_devices table
+type KnownDevice struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	UserID      int64     `gorm:"not null;uniqueIndex:idx_user_fingerprint"`
+	Fingerprint string    `gorm:"not null;uniqueIndex:idx_user_fingerprint"`
+	IP          string    `gorm:"column:ip;not null"`
+	UserAgent   string    `gorm:"not null"`
+	FirstSeenAt time.Time `gorm:"not null"`
+	LastSeenAt  time.Time `gorm:"not null"`
+}
+
+func (KnownDevice) TableName() string { return "user_known_devices" }
+
+type DeviceRepo struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepo(db *gorm.DB) *DeviceRepo {
+	return &DeviceRepo{db: db}
+}
+
+// Touch records a login from the device and reports whether it was new
+// and whether the user had any device before (to skip the very first login).
+func (r *DeviceRepo) Touch(userID int64, ip, userAgent string) (isNew bool, hadDevices bool, err error) {
+	var count int64
+	if err := r.db.Model(&KnownDevice{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return false, false, err
+	}
+
+	now := time.Now()
+	d := KnownDevice{
+		UserID:      userID,
+		Fingerprint: util.HashToken(ip + "|" + userAgent),
+		IP:          ip,
+		UserAgent:   userAgent,
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+	}
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&d)
+	if res.Error != nil {
+		return false, count > 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		err := r.db.Model(&KnownDevice{}).
+			Where("user_id = ? AND fingerprint = ?", userID, d.Fingerprint).
+			Update("last_seen_at", now).Error
+		return false, true, err
+	}
+	return true, count > 0, nil
+}
@@ -8,8 +8,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"ecommerce/internal/audit"
 	"ecommerce/internal/config"
 	"ecommerce/internal/mail"
+	"ecommerce/internal/oidc"
 	"ecommerce/internal/util"
 	"ecommerce/internal/domain/user"
 )
@@ -17,16 +19,42 @@ import (
 const (
 	OTPPurposeVerifyEmail   = "verify_email"
 	OTPPurposeResetPassword = "reset_password"
+	OTPPurposeLogin         = "login"
+	OTPPurposeChangeEmail   = "change_email"
+	OTPPurposeReauth        = "reauth"
 )
 
 type Dependencies struct {
-	Cfg     config.Config
-	JWT     *JWTManager
-	Users   *UserRepo
-	Refresh *RefreshRepo
-	Resets  *ResetRepo // (kept for compatibility; not used in OTP flow)
-	OTP     *OTPRepo
-	Mailer  mail.Mailer
+	Cfg      config.Config
+	JWT      *JWTManager
+	Users    *UserRepo
+	Refresh  *RefreshRepo
+	Resets   *ResetRepo // (kept for compatibility; not used in OTP flow)
+	OTP      *OTPRepo
+	Recovery *RecoveryRepo
+	Mailer   mail.Mailer
+
+	// OpenID Connect social login (keyed by provider name)
+	Identities *IdentityRepo
+	OIDC       map[string]*oidc.Client
+
+	// Failed login lockout and new-device detection
+	Throttle *ThrottleRepo
+	Devices  *DeviceRepo
+
+	Roles *RoleRepo
+	Audit *audit.Repo
+
+	Passwords *PasswordPolicy
+
+	// Guest cart merge on login/register (optional)
+	Carts CartMerger
+}
+
+// CartMerger folds the request's guest cart into the user's cart.
+// Implemented by cart.Handler; an interface because cart imports auth.
+type CartMerger interface {
+	MergeGuestCart(c *gin.Context, userID int64)
 }
 
 type Handler struct {
@@ -39,7 +67,7 @@ func NewHandler(d Dependencies) *Handler {
 
 type registerReq struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"`
 }
 
 type loginReq struct {
@@ -63,7 +91,7 @@ type forgotReq struct {
 type resetWithOTPReq struct {
 	Email       string `json:"email" binding:"required,email"`
 	OTP         string `json:"otp" binding:"required,len=6"`
-	NewPassword string `json:"new_password" binding:"required,min=8"`
+	NewPassword string `json:"new_password" binding:"required"`
 }
 
 func (h *Handler) Register(c *gin.Context) {
@@ -75,6 +103,10 @@ func (h *Handler) Register(c *gin.Context) {
 
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
 
+	if !h.passwordAllowed(c, req.Password, req.Email) {
+		return
+	}
+
 	pwHash, err := HashPassword(req.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hash failed"})
@@ -87,6 +119,10 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
+	if h.deps.Carts != nil {
+		h.deps.Carts.MergeGuestCart(c, u.ID)
+	}
+
 	otp, exp, err := h.issueOTP(u.ID, OTPPurposeVerifyEmail)
 	if err == nil {
 		_ = h.sendOTPEmail(u.Email, otp, exp, "Verify your email")
@@ -169,11 +205,19 @@ func (h *Handler) Login(c *gin.Context) {
 	}
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
 
+	if h.ipLocked(c) {
+		return
+	}
+
 	u, err := h.deps.Users.ByEmail(req.Email)
 	if err != nil || !u.IsActive {
+		h.recordLoginFailure(c, nil)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
 	}
+	if h.accountLocked(c, u) {
+		return
+	}
 
 	if !u.EmailVerified {
 		c.JSON(http.StatusForbidden, gin.H{"error": "email not verified"})
@@ -181,13 +225,64 @@ func (h *Handler) Login(c *gin.Context) {
 	}
 
 	if !CheckPassword(u.PasswordHash, req.Password) {
+		h.recordLoginFailure(c, &u)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
 	}
 
-	access, accessExp, _ := h.deps.JWT.SignAccess(u.ID, u.Role)
-	refresh, refreshExp, _ := h.deps.JWT.SignRefresh(u.ID, u.Role)
-	_ = h.deps.Refresh.Store(u.ID, HashToken(refresh), refreshExp)
+	// upgrade hashes made with an old algorithm or cost while we have the plaintext
+	if NeedsRehash(u.PasswordHash) {
+		if newHash, err := HashPassword(req.Password); err == nil {
+			_ = h.deps.Users.UpdatePassword(u.ID, newHash)
+		}
+	}
+
+	h.completeLogin(c, u)
+}
+
+// completeLogin returns an MFA challenge when 2FA is on, tokens otherwise
+func (h *Handler) completeLogin(c *gin.Context, u user.User) {
+	if u.TOTPEnabled {
+		challenge, exp, err := h.deps.JWT.SignMFAChallenge(u.ID, u.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "token issue failed"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_token":    challenge,
+			"mfa_exp":      exp,
+		})
+		return
+	}
+
+	h.respondWithTokens(c, u, false)
+}
+
+// respondWithTokens issues a new access/refresh pair for u
+func (h *Handler) respondWithTokens(c *gin.Context, u user.User, mfa bool) {
+	h.recordLoginSuccess(c, u)
+
+	perms, err := h.deps.Roles.PermissionsFor(u.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token issue failed"})
+		return
+	}
+	access, accessExp, err := h.deps.JWT.SignAccess(u.ID, u.Role, perms, mfa)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token issue failed"})
+		return
+	}
+	refresh, refreshExp, err := h.deps.JWT.SignRefresh(u.ID, u.Role, mfa)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token issue failed"})
+		return
+	}
+	_ = h.deps.Refresh.Store(u.ID, util.HashToken(refresh), refreshExp)
+
+	if h.deps.Carts != nil {
+		h.deps.Carts.MergeGuestCart(c, u.ID)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"user":          sanitizeUser(u),
@@ -198,6 +293,73 @@ func (h *Handler) Login(c *gin.Context) {
 	})
 }
 
+// Passwordless login: send a login OTP (privacy-safe)
+func (h *Handler) RequestLoginOTP(c *gin.Context) {
+	var req forgotReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+
+	u, err := h.deps.Users.ByEmail(req.Email)
+	if err != nil || !u.IsActive {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+		return
+	}
+
+	otp, exp, err := h.issueOTP(u.ID, OTPPurposeLogin)
+	if err == nil {
+		_ = h.sendOTPEmail(u.Email, otp, exp, "Your login code")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// Passwordless login: verify the OTP and issue the same tokens as Login
+func (h *Handler) VerifyLoginOTP(c *gin.Context) {
+	var req verifyOTPReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+
+	if h.ipLocked(c) {
+		return
+	}
+
+	u, err := h.deps.Users.ByEmail(req.Email)
+	if err != nil || !u.IsActive {
+		h.recordLoginFailure(c, nil)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired otp"})
+		return
+	}
+	if h.accountLocked(c, u) {
+		return
+	}
+
+	ok, err := h.verifyOTP(u.ID, OTPPurposeLogin, req.OTP)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		h.recordLoginFailure(c, &u)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired otp"})
+		return
+	}
+	_ = h.deps.OTP.Delete(u.ID, OTPPurposeLogin)
+
+	// receiving the code proves ownership of the address
+	if !u.EmailVerified {
+		_ = h.deps.Users.SetEmailVerified(u.ID)
+		u.EmailVerified = true
+	}
+
+	h.completeLogin(c, u)
+}
+
 // Rotate refresh token
 func (h *Handler) Refresh(c *gin.Context) {
 	var req refreshReq
@@ -212,17 +374,29 @@ func (h *Handler) Refresh(c *gin.Context) {
 		return
 	}
 
-	ok, err := h.deps.Refresh.IsValid(claims.UserID, HashToken(req.RefreshToken))
+	ok, err := h.deps.Refresh.IsValid(claims.UserID, util.HashToken(req.RefreshToken))
 	if err != nil || !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token expired or revoked"})
 		return
 	}
 
-	_ = h.deps.Refresh.Revoke(claims.UserID, HashToken(req.RefreshToken))
+	// role/permissions may have changed since login, so re-read the user
+	u, err := h.deps.Users.ByID(claims.UserID)
+	if err != nil || !u.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token expired or revoked"})
+		return
+	}
+	perms, err := h.deps.Roles.PermissionsFor(u.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token issue failed"})
+		return
+	}
+
+	_ = h.deps.Refresh.Revoke(claims.UserID, util.HashToken(req.RefreshToken))
 
-	access, accessExp, _ := h.deps.JWT.SignAccess(claims.UserID, claims.Role)
-	newRefresh, refreshExp, _ := h.deps.JWT.SignRefresh(claims.UserID, claims.Role)
-	_ = h.deps.Refresh.Store(claims.UserID, HashToken(newRefresh), refreshExp)
+	access, accessExp, _ := h.deps.JWT.SignAccess(u.ID, u.Role, perms, claims.MFA)
+	newRefresh, refreshExp, _ := h.deps.JWT.SignRefresh(u.ID, u.Role, claims.MFA)
+	_ = h.deps.Refresh.Store(u.ID, util.HashToken(newRefresh), refreshExp)
 
 	c.JSON(http.StatusOK, gin.H{
 		"access_token":  access,
@@ -240,7 +414,7 @@ func (h *Handler) Logout(c *gin.Context) {
 	}
 	claims, err := h.deps.JWT.ParseRefresh(req.RefreshToken)
 	if err == nil {
-		_ = h.deps.Refresh.Revoke(claims.UserID, HashToken(req.RefreshToken))
+		_ = h.deps.Refresh.Revoke(claims.UserID, util.HashToken(req.RefreshToken))
 	}
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
@@ -293,6 +467,10 @@ func (h *Handler) ResetPassword(c *gin.Context) {
 		return
 	}
 
+	if !h.passwordAllowed(c, req.NewPassword, u.Email) {
+		return
+	}
+
 	newHash, err := HashPassword(req.NewPassword)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hash failed"})
@@ -320,6 +498,12 @@ func (h *Handler) Me(c *gin.Context) {
 	c.JSON(http.StatusOK, sanitizeUser(u))
 }
 
+// Public keys for verifying access tokens (empty when using HS256)
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.deps.JWT.JWKS())
+}
+
 func sanitizeUser(u user.User) gin.H {
 	return gin.H{
 		"id":             u.ID,
@@ -327,11 +511,30 @@ func sanitizeUser(u user.User) gin.H {
 		"role":           u.Role,
 		"is_active":      u.IsActive,
 		"email_verified": u.EmailVerified,
+		"totp_enabled":   u.TOTPEnabled,
+		"display_name":   u.DisplayName,
+		"phone":          u.Phone,
 		"created_at":     u.CreatedAt,
 		"updated_at":     u.UpdatedAt,
 	}
 }
 
+// passwordAllowed responds 400 with every violated rule if the policy rejects pw
+func (h *Handler) passwordAllowed(c *gin.Context, pw, email string) bool {
+	policy := h.deps.Passwords
+	if policy == nil {
+		policy = &PasswordPolicy{MinLength: minPasswordLength}
+	}
+	if violations := policy.Validate(pw, email); len(violations) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "password does not meet the password policy",
+			"violations": violations,
+		})
+		return false
+	}
+	return true
+}
+
 func (h *Handler) issueOTP(userID int64, purpose string) (string, time.Time, error) {
 	otp, err := util.GenerateOTP6()
 	if err != nil {
@@ -347,7 +550,7 @@ func (h *Handler) issueOTP(userID int64, purpose string) (string, time.Time, err
 	if h.deps.OTP == nil {
 		return "", time.Time{}, errors.New("otp repo not configured")
 	}
-	if err := h.deps.OTP.Upsert(userID, purpose, HashToken(otp), expiresAt); err != nil {
+	if err := h.deps.OTP.Upsert(userID, purpose, util.HashToken(otp), expiresAt); err != nil {
 		return "", time.Time{}, err
 	}
 	return otp, expiresAt, nil
@@ -361,7 +564,7 @@ func (h *Handler) verifyOTP(userID int64, purpose string, otp string) (bool, err
 	if !ok || time.Now().After(exp) {
 		return false, nil
 	}
-	return HashToken(otp) == hash, nil
+	return util.HashToken(otp) == hash, nil
 }
 
 func (h *Handler) sendMailSafe(to, subject, body string) error {
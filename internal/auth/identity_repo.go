@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity is the GORM model for user_identities table
+type UserIdentity struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	UserID    int64     `gorm:"not null;index"`
+	Provider  string    `gorm:"type:text;not null;uniqueIndex:idx_provider_subject"`
+	Subject   string    `gorm:"type:text;not null;uniqueIndex:idx_provider_subject"`
+	Email     string    `gorm:"type:citext"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (UserIdentity) TableName() string { return "user_identities" }
+
+type IdentityRepo struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepo(db *gorm.DB) *IdentityRepo {
+	return &IdentityRepo{db: db}
+}
+
+// Find returns (identity, found, error)
+func (r *IdentityRepo) Find(provider, subject string) (UserIdentity, bool, error) {
+	var id UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&id).Error
+	if err == gorm.ErrRecordNotFound {
+		return UserIdentity{}, false, nil
+	}
+	if err != nil {
+		return UserIdentity{}, false, err
+	}
+	return id, true, nil
+}
+
+func (r *IdentityRepo) Link(userID int64, provider, subject, email string) error {
+	return r.db.Create(&UserIdentity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	}).Error
+}
+
+func (r *IdentityRepo) ListByUser(userID int64) ([]UserIdentity, error) {
+	var out []UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&out).Error
+	return out, err
+}
@@ -13,20 +13,38 @@ type JWTConfig struct {
 	RefreshSecret  string
 	AccessTTLMin   int
 	RefreshTTLDays int
+
+	// AccessKeys switches access tokens to RS256/EdDSA. When empty,
+	// access tokens are signed with AccessSecret (HS256).
+	AccessKeys []SigningKey
+	// HS256AcceptUntil keeps accepting kid-less HS256 access tokens after
+	// switching to AccessKeys, until this time (zero = not at all)
+	HS256AcceptUntil time.Time
 }
 
 type JWTManager struct {
-	cfg JWTConfig
+	cfg  JWTConfig
+	ring *keyRing
 }
 
 type Claims struct {
-	UserID int64  `json:"uid"`
-	Role   string `json:"role"`
+	UserID  int64    `json:"uid"`
+	Role    string   `json:"role"`
+	Perms   []string `json:"perms,omitempty"`   // resolved from the role at sign time (access only)
+	MFA     bool     `json:"mfa,omitempty"`     // second factor was verified at login
+	Purpose string   `json:"purpose,omitempty"` // set on non-session tokens (e.g. mfa challenge)
 	jwt.RegisteredClaims
 }
 
+const (
+	tokenPurposeMFA = "mfa"
+	mfaChallengeTTL = 5 * time.Minute
+)
+
 func NewJWTManager(cfg JWTConfig) *JWTManager {
-	return &JWTManager{cfg: cfg}
+	m := &JWTManager{cfg: cfg}
+	m.ring = newKeyRing(cfg.AccessKeys, m.AccessTTL())
+	return m
 }
 
 func (m *JWTManager) AccessTTL() time.Duration {
@@ -37,29 +55,44 @@ func (m *JWTManager) RefreshTTL() time.Duration {
 	return time.Duration(m.cfg.RefreshTTLDays) * 24 * time.Hour
 }
 
-func (m *JWTManager) SignAccess(userID int64, role string) (string, time.Time, error) {
+func (m *JWTManager) SignAccess(userID int64, role string, perms []string, mfa bool) (string, time.Time, error) {
 	now := time.Now()
 	exp := now.Add(m.AccessTTL())
 	claims := Claims{
 		UserID: userID,
 		Role:   role,
+		Perms:  perms,
+		MFA:    mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    m.cfg.Issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(exp),
 		},
 	}
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	s, err := t.SignedString([]byte(m.cfg.AccessSecret))
+
+	if m.ring.empty() {
+		t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		s, err := t.SignedString([]byte(m.cfg.AccessSecret))
+		return s, exp, err
+	}
+
+	key, err := m.ring.current(now)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	t := jwt.NewWithClaims(key.Method, claims)
+	t.Header["kid"] = key.ID
+	s, err := t.SignedString(key.Private)
 	return s, exp, err
 }
 
-func (m *JWTManager) SignRefresh(userID int64, role string) (string, time.Time, error) {
+func (m *JWTManager) SignRefresh(userID int64, role string, mfa bool) (string, time.Time, error) {
 	now := time.Now()
 	exp := now.Add(m.RefreshTTL())
 	claims := Claims{
 		UserID: userID,
 		Role:   role,
+		MFA:    mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    m.cfg.Issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -72,20 +105,107 @@ func (m *JWTManager) SignRefresh(userID int64, role string) (string, time.Time,
 }
 
 func (m *JWTManager) ParseAccess(tokenStr string) (*Claims, error) {
-	return m.parse(tokenStr, []byte(m.cfg.AccessSecret))
+	claims, err := m.parseAccess(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("not an access token")
+	}
+	return claims, nil
+}
+
+func (m *JWTManager) parseAccess(tokenStr string) (*Claims, error) {
+	if m.ring.empty() {
+		return m.parse(tokenStr, []byte(m.cfg.AccessSecret))
+	}
+	return m.parseClaims(tokenStr, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			// HS256 tokens issued before switching to asymmetric keys,
+			// only during the configured changeover
+			if t.Method == jwt.SigningMethodHS256 && m.cfg.AccessSecret != "" &&
+				time.Now().Before(m.cfg.HS256AcceptUntil) {
+				return []byte(m.cfg.AccessSecret), nil
+			}
+			return nil, errors.New("missing key id")
+		}
+		key, ok := m.ring.verifier(kid, time.Now())
+		if !ok {
+			return nil, errors.New("unknown or retired key id")
+		}
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.Public(), nil
+	})
 }
 
 func (m *JWTManager) ParseRefresh(tokenStr string) (*Claims, error) {
-	return m.parse(tokenStr, []byte(m.cfg.RefreshSecret))
+	claims, err := m.parse(tokenStr, []byte(m.cfg.RefreshSecret))
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("not a refresh token")
+	}
+	return claims, nil
+}
+
+// SignMFAChallenge issues the short-lived token returned by Login when the
+// user still has to present a second factor.
+func (m *JWTManager) SignMFAChallenge(userID int64, role string) (string, time.Time, error) {
+	now := time.Now()
+	exp := now.Add(mfaChallengeTTL)
+	claims := Claims{
+		UserID:  userID,
+		Role:    role,
+		Purpose: tokenPurposeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.cfg.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, err := t.SignedString([]byte(m.cfg.RefreshSecret))
+	return s, exp, err
+}
+
+func (m *JWTManager) ParseMFAChallenge(tokenStr string) (*Claims, error) {
+	claims, err := m.parse(tokenStr, []byte(m.cfg.RefreshSecret))
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != tokenPurposeMFA {
+		return nil, errors.New("not an mfa challenge token")
+	}
+	return claims, nil
+}
+
+// JWKS returns the public access token keys for other services.
+func (m *JWTManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if m.ring.empty() {
+		return set
+	}
+	for _, k := range m.ring.published(time.Now()) {
+		set.Keys = append(set.Keys, toJWK(k))
+	}
+	return set
 }
 
 func (m *JWTManager) parse(tokenStr string, secret []byte) (*Claims, error) {
-	tok, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (any, error) {
+	return m.parseClaims(tokenStr, func(t *jwt.Token) (any, error) {
 		if t.Method != jwt.SigningMethodHS256 {
 			return nil, errors.New("unexpected signing method")
 		}
 		return secret, nil
 	})
+}
+
+func (m *JWTManager) parseClaims(tokenStr string, keyFunc jwt.Keyfunc) (*Claims, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &Claims{}, keyFunc)
 	if err != nil {
 		return nil, err
 	}
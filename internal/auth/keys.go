@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is one asymmetric key used to sign access tokens.
+// Keys are rotated by activation time: the newest active key signs,
+// older keys keep verifying until tokens they signed have expired.
+type SigningKey struct {
+	ID       string
+	Method   jwt.SigningMethod
+	Private  crypto.Signer
+	ActiveAt time.Time
+
+	retireAt time.Time // zero = never retired
+}
+
+func (k SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// ParseSigningKeys parses JWT_SIGNING_KEYS, a comma separated list of
+// "kid=path/to/key.pem[@RFC3339 activation time]" entries.
+func ParseSigningKeys(spec string) ([]SigningKey, error) {
+	var out []SigningKey
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, rest, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || rest == "" {
+			return nil, fmt.Errorf("invalid signing key entry %q", entry)
+		}
+		if seen[kid] {
+			return nil, fmt.Errorf("duplicate signing key id %q", kid)
+		}
+		seen[kid] = true
+
+		path, activeStr, _ := strings.Cut(rest, "@")
+		var activeAt time.Time
+		if activeStr != "" {
+			t, err := time.Parse(time.RFC3339, activeStr)
+			if err != nil {
+				return nil, fmt.Errorf("signing key %s: invalid activation time: %w", kid, err)
+			}
+			activeAt = t
+		}
+
+		k, err := LoadSigningKey(kid, path)
+		if err != nil {
+			return nil, err
+		}
+		k.ActiveAt = activeAt
+		out = append(out, k)
+	}
+	return out, nil
+}
+
+// LoadSigningKey reads a PEM encoded RSA or Ed25519 private key.
+func LoadSigningKey(kid, path string) (SigningKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("signing key %s: %w", kid, err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("signing key %s: no PEM data", kid)
+	}
+
+	var priv any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("signing key %s: %w", kid, err)
+	}
+
+	switch p := priv.(type) {
+	case *rsa.PrivateKey:
+		return SigningKey{ID: kid, Method: jwt.SigningMethodRS256, Private: p}, nil
+	case ed25519.PrivateKey:
+		return SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, Private: p}, nil
+	default:
+		return SigningKey{}, fmt.Errorf("signing key %s: unsupported key type %T", kid, priv)
+	}
+}
+
+// keyRing holds the access token signing keys ordered by activation time.
+type keyRing struct {
+	keys []SigningKey
+}
+
+// newKeyRing sorts keys and sets each key's retirement to the activation of
+// its successor plus the access token TTL, so tokens it signed stay valid.
+func newKeyRing(keys []SigningKey, accessTTL time.Duration) *keyRing {
+	sorted := append([]SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveAt.Before(sorted[j].ActiveAt)
+	})
+	for i := 0; i < len(sorted)-1; i++ {
+		sorted[i].retireAt = sorted[i+1].ActiveAt.Add(accessTTL)
+	}
+	return &keyRing{keys: sorted}
+}
+
+func (r *keyRing) empty() bool {
+	return r == nil || len(r.keys) == 0
+}
+
+// current returns the newest key that is already active.
+func (r *keyRing) current(now time.Time) (SigningKey, error) {
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if !r.keys[i].ActiveAt.After(now) {
+			return r.keys[i], nil
+		}
+	}
+	return SigningKey{}, errors.New("no active signing key")
+}
+
+// verifier returns the key for kid if it may still verify tokens.
+func (r *keyRing) verifier(kid string, now time.Time) (SigningKey, bool) {
+	for _, k := range r.keys {
+		if k.ID != kid {
+			continue
+		}
+		if k.ActiveAt.After(now) {
+			return SigningKey{}, false
+		}
+		if !k.retireAt.IsZero() && now.After(k.retireAt) {
+			return SigningKey{}, false
+		}
+		return k, true
+	}
+	return SigningKey{}, false
+}
+
+// published returns every key that is not retired yet, including scheduled
+// ones, so verifiers can cache them before rotation happens.
+func (r *keyRing) published(now time.Time) []SigningKey {
+	var out []SigningKey
+	for _, k := range r.keys {
+		if !k.retireAt.IsZero() && now.After(k.retireAt) {
+			continue
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+// JWK is a single public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func toJWK(k SigningKey) JWK {
+	j := JWK{Use: "sig", Alg: k.Method.Alg(), Kid: k.ID}
+	switch pub := k.Public().(type) {
+	case *rsa.PublicKey:
+		j.Kty = "RSA"
+		j.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		j.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		j.Kty = "OKP"
+		j.Crv = "Ed25519"
+		j.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return j
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testKey(t *testing.T, kid string, activeAt time.Time) SigningKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, Private: priv, ActiveAt: activeAt}
+}
+
+func writeKeyFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseSigningKeys(t *testing.T) {
+	dir := t.TempDir()
+	a := writeKeyFile(t, dir, "a.pem")
+	b := writeKeyFile(t, dir, "b.pem")
+
+	keys, err := ParseSigningKeys("k1=" + a + ", k2=" + b + "@2030-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].ID != "k1" || !keys[0].ActiveAt.IsZero() ||
+		keys[1].ID != "k2" || keys[1].ActiveAt.Year() != 2030 {
+		t.Errorf("unexpected keys: %+v", keys)
+	}
+
+	if _, err := ParseSigningKeys("k1=" + a + ",k1=" + b); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("duplicate kid: err = %v", err)
+	}
+	if _, err := ParseSigningKeys("k1=" + a + "@yesterday"); err == nil {
+		t.Error("bad activation time: expected an error")
+	}
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ttl := 15 * time.Minute
+	// given out of order; the ring sorts by activation
+	ring := newKeyRing([]SigningKey{testKey(t, "new", t0.Add(time.Hour)), testKey(t, "old", time.Time{})}, ttl)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		current   string
+		verifies  []string
+		rejects   []string
+		published int
+	}{
+		{"before rotation", t0, "old", []string{"old"}, []string{"new"}, 2},
+		{"after rotation, old key still verifies", t0.Add(time.Hour + ttl), "new", []string{"old", "new"}, nil, 2},
+		{"old key retired", t0.Add(time.Hour + ttl + time.Second), "new", []string{"new"}, []string{"old"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cur, err := ring.current(tt.now)
+			if err != nil || cur.ID != tt.current {
+				t.Errorf("current = %q, %v; want %q", cur.ID, err, tt.current)
+			}
+			for _, kid := range tt.verifies {
+				if _, ok := ring.verifier(kid, tt.now); !ok {
+					t.Errorf("%s should verify", kid)
+				}
+			}
+			for _, kid := range tt.rejects {
+				if _, ok := ring.verifier(kid, tt.now); ok {
+					t.Errorf("%s should not verify", kid)
+				}
+			}
+			if n := len(ring.published(tt.now)); n != tt.published {
+				t.Errorf("published %d keys, want %d", n, tt.published)
+			}
+		})
+	}
+
+	if _, err := newKeyRing([]SigningKey{testKey(t, "later", t0)}, ttl).current(t0.Add(-time.Second)); err == nil {
+		t.Error("no active key: expected an error")
+	}
+}
+
+func TestParseAccessWithKeyRing(t *testing.T) {
+	cfg := JWTConfig{Issuer: "test", AccessSecret: "shared", AccessTTLMin: 15}
+	hsToken, _, err := NewJWTManager(cfg).SignAccess(1, "user", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.AccessKeys = []SigningKey{testKey(t, "k1", time.Time{})}
+	m := NewJWTManager(cfg)
+	tok, _, err := m.SignAccess(1, "user", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := m.ParseAccess(tok); err != nil || c.UserID != 1 {
+		t.Fatalf("ParseAccess = %+v, %v", c, err)
+	}
+
+	// a token signed by a key the ring doesn't know
+	other := NewJWTManager(JWTConfig{Issuer: "test", AccessTTLMin: 15, AccessKeys: []SigningKey{testKey(t, "k1", time.Time{})}})
+	forged, _, err := other.SignAccess(1, "admin", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ParseAccess(forged); err == nil {
+		t.Error("token from another key with the same kid: expected an error")
+	}
+
+	// kid-less HS256 tokens only during the changeover window
+	if _, err := m.ParseAccess(hsToken); err == nil {
+		t.Error("HS256 token without a cutoff: expected an error")
+	}
+	cfg.HS256AcceptUntil = time.Now().Add(time.Hour)
+	if _, err := NewJWTManager(cfg).ParseAccess(hsToken); err != nil {
+		t.Errorf("HS256 token before the cutoff: %v", err)
+	}
+	cfg.HS256AcceptUntil = time.Now().Add(-time.Second)
+	if _, err := NewJWTManager(cfg).ParseAccess(hsToken); err == nil {
+		t.Error("HS256 token after the cutoff: expected an error")
+	}
+}
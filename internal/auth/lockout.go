@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/domain/user"
+)
+
+func userThrottleKey(userID int64) string { return "user:" + strconv.FormatInt(userID, 10) }
+func ipThrottleKey(ip string) string      { return "ip:" + ip }
+
+func (h *Handler) userLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		MaxFailures: h.deps.Cfg.LoginMaxFailures,
+		BaseLockout: time.Duration(h.deps.Cfg.LoginLockoutBaseMin) * time.Minute,
+		MaxLockout:  time.Duration(h.deps.Cfg.LoginLockoutMaxMin) * time.Minute,
+	}
+}
+
+func (h *Handler) ipLockoutPolicy() LockoutPolicy {
+	p := h.userLockoutPolicy()
+	p.MaxFailures = h.deps.Cfg.LoginIPMaxFailures
+	return p
+}
+
+// ipLocked aborts with 429 when the client IP is locked
+func (h *Handler) ipLocked(c *gin.Context) bool {
+	if h.deps.Throttle == nil {
+		return false
+	}
+	if until, locked := h.deps.Throttle.LockedUntil(ipThrottleKey(c.ClientIP())); locked {
+		respondLocked(c, until)
+		return true
+	}
+	return false
+}
+
+// accountLocked aborts with 429 when the account is locked
+func (h *Handler) accountLocked(c *gin.Context, u user.User) bool {
+	if h.deps.Throttle == nil {
+		return false
+	}
+	if until, locked := h.deps.Throttle.LockedUntil(userThrottleKey(u.ID)); locked {
+		respondLocked(c, until)
+		return true
+	}
+	return false
+}
+
+func respondLocked(c *gin.Context, until time.Time) {
+	retry := int(math.Ceil(time.Until(until).Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retry))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":        "too many failed attempts, try again later",
+		"locked_until": until,
+	})
+}
+
+// recordLoginFailure counts a failed attempt for the IP and, if known, the account
+func (h *Handler) recordLoginFailure(c *gin.Context, u *user.User) {
+	if h.deps.Throttle == nil {
+		return
+	}
+	_, _, _ = h.deps.Throttle.RecordFailure(ipThrottleKey(c.ClientIP()), nil, h.ipLockoutPolicy())
+	if u == nil {
+		return
+	}
+
+	until, locked, err := h.deps.Throttle.RecordFailure(userThrottleKey(u.ID), &u.ID, h.userLockoutPolicy())
+	if err == nil && locked {
+		body := "Your account was temporarily locked after several failed sign-in attempts.\n\n" +
+			"Locked until: " + until.Format(time.RFC1123) + "\n" +
+			"Last attempt from IP: " + c.ClientIP() + "\n\n" +
+			"If this wasn't you, consider resetting your password."
+		_ = h.sendMailSafe(u.Email, "Your account was temporarily locked", body)
+	}
+}
+
+// recordLoginSuccess clears the account's failures and warns about new devices
+func (h *Handler) recordLoginSuccess(c *gin.Context, u user.User) {
+	if h.deps.Throttle != nil {
+		_ = h.deps.Throttle.Reset(userThrottleKey(u.ID))
+	}
+	if h.deps.Devices == nil {
+		return
+	}
+
+	ip, ua := c.ClientIP(), c.Request.UserAgent()
+	isNew, hadDevices, err := h.deps.Devices.Touch(u.ID, ip, ua)
+	if err != nil || !isNew || !hadDevices {
+		return
+	}
+	body := fmt.Sprintf("We noticed a new sign-in to your account.\n\n"+
+		"Time: %s\nIP address: %s\nDevice: %s\n\n"+
+		"If this was you, you can ignore this email. Otherwise reset your password now.",
+		time.Now().Format(time.RFC1123), ip, ua)
+	_ = h.sendMailSafe(u.Email, "New sign-in to your account", body)
+}
+
+// Admin: accounts and IPs currently locked out
+func (h *Handler) AdminListLocked(c *gin.Context) {
+	accounts, err := h.deps.Throttle.ListLockedAccounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list locked accounts"})
+		return
+	}
+	ips, err := h.deps.Throttle.ListLockedIPs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list locked accounts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"accounts": accounts, "ips": ips})
+}
+
+// Admin: lift an account lockout early
+func (h *Handler) AdminUnlock(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.deps.Throttle.Reset(userThrottleKey(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlock"})
+		return
+	}
+	h.audit(c, "user.unlock", id, nil)
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/domain/user"
+	"ecommerce/internal/util"
+)
+
+const (
+	recoveryCodeCount = 10
+	totpSkewSteps     = 1
+)
+
+type mfaCodeReq struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type loginMFAReq struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// Setup2FA creates a new (not yet enabled) TOTP secret for the current user
+func (h *Handler) Setup2FA(c *gin.Context) {
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if u.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication already enabled"})
+		return
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "secret generation failed"})
+		return
+	}
+	if err := h.deps.Users.SetTOTPSecret(u.ID, secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": util.TOTPURI(h.deps.Cfg.JWTIssuer, u.Email, secret),
+	})
+}
+
+// Enable2FA confirms the pending secret with a code and returns recovery codes once
+func (h *Handler) Enable2FA(c *gin.Context) {
+	var req mfaCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if u.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication already enabled"})
+		return
+	}
+	if u.TOTPSecret == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "run 2fa setup first"})
+		return
+	}
+	if !h.checkTOTP(u, req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+
+	codes, err := h.newRecoveryCodes(u.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create recovery codes"})
+		return
+	}
+	if err := h.deps.Users.EnableTOTP(u.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enable 2fa"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ok":             true,
+		"recovery_codes": codes,
+		"message":        "Two-factor authentication enabled. Log in again to get an MFA session.",
+	})
+}
+
+// Disable2FA turns 2FA off after checking a TOTP or recovery code
+func (h *Handler) Disable2FA(c *gin.Context) {
+	var req mfaCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if !u.TOTPEnabled {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+		return
+	}
+	if !h.checkSecondFactor(u, req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+
+	if err := h.deps.Users.DisableTOTP(u.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to disable 2fa"})
+		return
+	}
+	_ = h.deps.Recovery.DeleteAll(u.ID)
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes (requires a TOTP code)
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req mfaCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if !u.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication not enabled"})
+		return
+	}
+	if !h.checkTOTP(u, req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+
+	codes, err := h.newRecoveryCodes(u.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create recovery codes"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// LoginMFA completes a login started by Login when 2FA is enabled
+func (h *Handler) LoginMFA(c *gin.Context) {
+	var req loginMFAReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := h.deps.JWT.ParseMFAChallenge(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired mfa token"})
+		return
+	}
+
+	u, err := h.deps.Users.ByID(claims.UserID)
+	if err != nil || !u.IsActive || !u.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+	if h.ipLocked(c) || h.accountLocked(c, u) {
+		return
+	}
+	if !h.checkSecondFactor(u, req.Code) {
+		h.recordLoginFailure(c, &u)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid code"})
+		return
+	}
+
+	h.respondWithTokens(c, u, true)
+}
+
+func (h *Handler) currentUser(c *gin.Context) (user.User, bool) {
+	uidAny, _ := c.Get(CtxUserIDKey)
+	uid, _ := uidAny.(int64)
+
+	u, err := h.deps.Users.ByID(uid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return user.User{}, false
+	}
+	return u, true
+}
+
+// checkTOTP validates a TOTP code and burns its time step
+func (h *Handler) checkTOTP(u user.User, code string) bool {
+	if u.TOTPSecret == nil {
+		return false
+	}
+	step, ok := util.ValidateTOTP(*u.TOTPSecret, strings.TrimSpace(code), time.Now(), totpSkewSteps)
+	if !ok {
+		return false
+	}
+	fresh, err := h.deps.Users.UseTOTPStep(u.ID, step)
+	return err == nil && fresh
+}
+
+// checkSecondFactor accepts either a TOTP code or an unused recovery code
+func (h *Handler) checkSecondFactor(u user.User, code string) bool {
+	if h.checkTOTP(u, code) {
+		return true
+	}
+	ok, err := h.deps.Recovery.Consume(u.ID, util.HashToken(normalizeRecoveryCode(code)))
+	return err == nil && ok
+}
+
+func (h *Handler) newRecoveryCodes(userID int64) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.RandomCode(10)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, util.HashToken(code))
+	}
+	if err := h.deps.Recovery.Replace(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(s)
+}
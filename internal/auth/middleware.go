@@ -9,6 +9,8 @@ import (
 
 const CtxUserIDKey = "user_id"
 const CtxRoleKey = "role"
+const CtxMFAKey = "mfa"
+const CtxPermissionsKey = "permissions"
 
 func AuthMiddleware(jwtMgr *JWTManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -25,6 +27,35 @@ func AuthMiddleware(jwtMgr *JWTManager) gin.HandlerFunc {
 		}
 		c.Set(CtxUserIDKey, claims.UserID)
 		c.Set(CtxRoleKey, claims.Role)
+		c.Set(CtxMFAKey, claims.MFA)
+		c.Set(CtxPermissionsKey, claims.Perms)
+		c.Next()
+	}
+}
+
+// OptionalAuth sets the user context when a valid bearer token is present
+// and lets anonymous requests through (e.g. guest carts).
+func OptionalAuth(jwtMgr *JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := c.GetHeader("Authorization")
+		if h == "" {
+			c.Next()
+			return
+		}
+		if !strings.HasPrefix(h, "Bearer ") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+		claims, err := jwtMgr.ParseAccess(strings.TrimPrefix(h, "Bearer "))
+		if err != nil {
+			// a stale token must not silently fall back to a guest cart
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid access token"})
+			return
+		}
+		c.Set(CtxUserIDKey, claims.UserID)
+		c.Set(CtxRoleKey, claims.Role)
+		c.Set(CtxMFAKey, claims.MFA)
+		c.Set(CtxPermissionsKey, claims.Perms)
 		c.Next()
 	}
 }
@@ -39,3 +70,34 @@ func RequireRole(role string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequirePermission allows the request only if the access token carries perm
+func RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c, perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func HasPermission(c *gin.Context, perm string) bool {
+	for _, p := range c.GetStringSlice(CtxPermissionsKey) {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireMFA rejects sessions that were not established with a second factor
+func RequireMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool(CtxMFAKey) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "two-factor authentication required"})
+			return
+		}
+		c.Next()
+	}
+}
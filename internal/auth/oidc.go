@@ -0,0 +1,302 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"ecommerce/internal/domain/user"
+	"ecommerce/internal/oidc"
+	"ecommerce/internal/util"
+)
+
+const (
+	oidcFlowCookie = "oidc_flow"
+	oidcFlowTTL    = 10 * time.Minute
+)
+
+// oidcFlow is kept in a signed cookie between start and callback,
+// so no server-side state is needed for state/nonce/PKCE.
+type oidcFlow struct {
+	Provider string `json:"prv"`
+	State    string `json:"st"`
+	Nonce    string `json:"nc"`
+	Verifier string `json:"cv"`
+	jwt.RegisteredClaims
+}
+
+// OIDCStart redirects the browser to the provider's authorization endpoint
+func (h *Handler) OIDCStart(c *gin.Context) {
+	client, ok := h.deps.OIDC[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
+		return
+	}
+
+	flow := oidcFlow{Provider: client.Name()}
+	for _, dst := range []*string{&flow.State, &flow.Nonce, &flow.Verifier} {
+		v, err := util.RandomToken(32)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "random failed"})
+			return
+		}
+		*dst = v
+	}
+
+	authURL, err := client.AuthCodeURL(c.Request.Context(), flow.State, flow.Nonce, flow.Verifier)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "provider unavailable"})
+		return
+	}
+
+	signed, err := h.deps.JWT.signOIDCFlow(flow)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token issue failed"})
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, signed, int(oidcFlowTTL.Seconds()), "/api/auth/oidc", "", h.secureCookies(), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback validates state, exchanges the code and logs the user in
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	client, ok := h.deps.OIDC[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
+		return
+	}
+	if e := c.Query("error"); e != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "provider error: " + e})
+		return
+	}
+
+	raw, err := c.Cookie(oidcFlowCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "login session expired"})
+		return
+	}
+	c.SetCookie(oidcFlowCookie, "", -1, "/api/auth/oidc", "", h.secureCookies(), true)
+
+	flow, err := h.deps.JWT.parseOIDCFlow(raw)
+	if err != nil || flow.Provider != client.Name() || flow.State != c.Query("state") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state"})
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing code"})
+		return
+	}
+
+	idc, err := client.Exchange(c.Request.Context(), code, flow.Verifier, flow.Nonce)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "provider login failed"})
+		return
+	}
+
+	u, err := h.userForIdentity(client.Name(), idc)
+	if errors.Is(err, errOIDCLinkRequired) {
+		h.respondLinkRequired(c, client.Name(), idc, u)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if !u.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	h.completeLogin(c, u)
+}
+
+// errOIDCLinkRequired means the provider email belongs to an existing
+// account that has not been linked to this identity yet
+var errOIDCLinkRequired = errors.New("account exists, sign in to link it")
+
+// userForIdentity resolves the linked user or creates a new verified user
+// on first login. An existing account with the same email is never linked
+// here: the provider only asserts the email, so the owner has to prove
+// control of the account first (see OIDCLink).
+func (h *Handler) userForIdentity(provider string, idc *oidc.IDClaims) (user.User, error) {
+	ident, found, err := h.deps.Identities.Find(provider, idc.Subject)
+	if err != nil {
+		return user.User{}, errors.New("identity lookup failed")
+	}
+	if found {
+		return h.deps.Users.ByID(ident.UserID)
+	}
+
+	email := strings.TrimSpace(strings.ToLower(idc.Email))
+	if email == "" || !idc.EmailVerified {
+		return user.User{}, errors.New("provider did not return a verified email")
+	}
+
+	if u, err := h.deps.Users.ByEmail(email); err == nil {
+		return u, errOIDCLinkRequired
+	}
+
+	// first login: the account gets a random password nobody knows
+	pw, err := util.RandomToken(32)
+	if err != nil {
+		return user.User{}, err
+	}
+	pwHash, err := HashPassword(pw)
+	if err != nil {
+		return user.User{}, err
+	}
+	u, err := h.deps.Users.Create(email, pwHash, "user")
+	if err != nil {
+		return user.User{}, errors.New("account creation failed")
+	}
+	_ = h.deps.Users.SetEmailVerified(u.ID)
+	u.EmailVerified = true
+
+	if err := h.deps.Identities.Link(u.ID, provider, idc.Subject, email); err != nil {
+		return user.User{}, errors.New("identity link failed")
+	}
+	return u, nil
+}
+
+// respondLinkRequired hands out a short-lived link token; the client sends
+// it back to OIDCLink together with the account password.
+func (h *Handler) respondLinkRequired(c *gin.Context, provider string, idc *oidc.IDClaims, u user.User) {
+	token, exp, err := h.deps.JWT.signOIDCLink(oidcLink{
+		Provider:   provider,
+		ExternalID: idc.Subject,
+		Email:      u.Email,
+		UserID:     u.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token issue failed"})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error":         errOIDCLinkRequired.Error(),
+		"link_required": true,
+		"link_token":    token,
+		"link_exp":      exp,
+		"email":         u.Email,
+	})
+}
+
+type oidcLinkReq struct {
+	LinkToken string `json:"link_token" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+}
+
+// OIDCLink links a provider identity to an existing account after the
+// owner signs in with the account password, then logs them in.
+func (h *Handler) OIDCLink(c *gin.Context) {
+	var req oidcLinkReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	link, err := h.deps.JWT.parseOIDCLink(req.LinkToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired link token"})
+		return
+	}
+
+	if h.ipLocked(c) {
+		return
+	}
+	u, err := h.deps.Users.ByID(link.UserID)
+	if err != nil || !u.IsActive || u.Email != link.Email {
+		h.recordLoginFailure(c, nil)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+	if h.accountLocked(c, u) {
+		return
+	}
+	if !CheckPassword(u.PasswordHash, req.Password) {
+		h.recordLoginFailure(c, &u)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	if err := h.deps.Identities.Link(u.ID, link.Provider, link.ExternalID, link.Email); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "identity link failed (may be linked already)"})
+		return
+	}
+	_ = h.sendMailSafe(u.Email, "A sign-in provider was linked to your account",
+		"You can now sign in with "+link.Provider+". If this wasn't you, reset your password and contact support.")
+
+	// the password proves ownership of the address
+	if !u.EmailVerified {
+		_ = h.deps.Users.SetEmailVerified(u.ID)
+		u.EmailVerified = true
+	}
+	h.completeLogin(c, u)
+}
+
+func (h *Handler) secureCookies() bool {
+	return h.deps.Cfg.AppEnv != "dev"
+}
+
+// oidcLink carries a verified provider identity waiting to be linked
+type oidcLink struct {
+	Provider   string `json:"prv"`
+	ExternalID string `json:"ext"` // provider subject
+	Email      string `json:"email"`
+	UserID     int64  `json:"uid"`
+	jwt.RegisteredClaims
+}
+
+func (m *JWTManager) signOIDCLink(l oidcLink) (string, time.Time, error) {
+	now := time.Now()
+	exp := now.Add(oidcFlowTTL)
+	l.Issuer = m.cfg.Issuer
+	l.Subject = "oidc_link"
+	l.IssuedAt = jwt.NewNumericDate(now)
+	l.ExpiresAt = jwt.NewNumericDate(exp)
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, l)
+	s, err := t.SignedString([]byte(m.cfg.RefreshSecret))
+	return s, exp, err
+}
+
+func (m *JWTManager) parseOIDCLink(tokenStr string) (*oidcLink, error) {
+	l := &oidcLink{}
+	tok, err := jwt.ParseWithClaims(tokenStr, l, func(t *jwt.Token) (any, error) {
+		return []byte(m.cfg.RefreshSecret), nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithSubject("oidc_link"))
+	if err != nil {
+		return nil, err
+	}
+	if !tok.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return l, nil
+}
+
+func (m *JWTManager) signOIDCFlow(f oidcFlow) (string, error) {
+	now := time.Now()
+	f.Issuer = m.cfg.Issuer
+	f.Subject = "oidc"
+	f.IssuedAt = jwt.NewNumericDate(now)
+	f.ExpiresAt = jwt.NewNumericDate(now.Add(oidcFlowTTL))
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, f)
+	return t.SignedString([]byte(m.cfg.RefreshSecret))
+}
+
+func (m *JWTManager) parseOIDCFlow(tokenStr string) (*oidcFlow, error) {
+	f := &oidcFlow{}
+	tok, err := jwt.ParseWithClaims(tokenStr, f, func(t *jwt.Token) (any, error) {
+		return []byte(m.cfg.RefreshSecret), nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithSubject("oidc"))
+	if err != nil {
+		return nil, err
+	}
+	if !tok.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return f, nil
+}
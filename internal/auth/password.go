@@ -1,23 +1,171 @@
 package auth
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+
+	"ecommerce/internal/config"
+)
+
+const (
+	HashAlgoBcrypt   = "bcrypt"
+	HashAlgoArgon2id = "argon2id"
 )
 
+// PasswordHashConfig selects the algorithm for new hashes. Existing hashes
+// in any supported format keep working and are upgraded on next login.
+type PasswordHashConfig struct {
+	Algorithm   string
+	BcryptCost  int
+	Argon2Time  uint32
+	Argon2MemKB uint32
+	Argon2Par   uint8
+	Argon2Salt  uint32
+	Argon2Key   uint32
+}
+
+var hashCfg = PasswordHashConfig{
+	Algorithm:  HashAlgoBcrypt,
+	BcryptCost: bcrypt.DefaultCost,
+}
+
+// SetPasswordHashing configures HashPassword/NeedsRehash; call once at
+// startup. Invalid settings are rejected and leave the current ones.
+func SetPasswordHashing(c PasswordHashConfig) error {
+	if c.BcryptCost == 0 {
+		c.BcryptCost = bcrypt.DefaultCost
+	}
+	if c.Argon2Salt == 0 {
+		c.Argon2Salt = 16
+	}
+	if c.Argon2Key == 0 {
+		c.Argon2Key = 32
+	}
+	switch c.Algorithm {
+	case HashAlgoBcrypt:
+		if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case HashAlgoArgon2id:
+		// argon2.IDKey panics on a zero time or parallelism
+		if c.Argon2Time < 1 || c.Argon2Par < 1 {
+			return errors.New("argon2 time and parallelism must be at least 1")
+		}
+		if c.Argon2MemKB < 8*uint32(c.Argon2Par) {
+			return errors.New("argon2 memory must be at least 8 KiB per thread")
+		}
+	default:
+		return fmt.Errorf("unknown password hash algorithm %q", c.Algorithm)
+	}
+	hashCfg = c
+	return nil
+}
+
+// ConfigurePasswordHashing applies the hashing settings from cfg
+func ConfigurePasswordHashing(cfg config.Config) error {
+	// check ranges before narrowing so large values aren't truncated
+	if cfg.Argon2Time < 0 || cfg.Argon2MemoryKB < 0 || cfg.Argon2Time > math.MaxUint32 || cfg.Argon2MemoryKB > math.MaxUint32 {
+		return errors.New("argon2 time and memory out of range")
+	}
+	if cfg.Argon2Parallelism < 0 || cfg.Argon2Parallelism > math.MaxUint8 {
+		return fmt.Errorf("argon2 parallelism must be between 1 and %d", math.MaxUint8)
+	}
+	return SetPasswordHashing(PasswordHashConfig{
+		Algorithm:   cfg.PasswordHashAlgo,
+		BcryptCost:  cfg.BcryptCost,
+		Argon2Time:  uint32(cfg.Argon2Time),
+		Argon2MemKB: uint32(cfg.Argon2MemoryKB),
+		Argon2Par:   uint8(cfg.Argon2Parallelism),
+	})
+}
+
 func HashPassword(plain string) (string, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if hashCfg.Algorithm == HashAlgoArgon2id {
+		return hashArgon2id(plain, hashCfg)
+	}
+	b, err := bcrypt.GenerateFromPassword([]byte(plain), hashCfg.BcryptCost)
 	return string(b), err
 }
 
 func CheckPassword(hash, plain string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		p, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false
+		}
+		got := argon2.IDKey([]byte(plain), salt, p.Argon2Time, p.Argon2MemKB, p.Argon2Par, uint32(len(key)))
+		return subtle.ConstantTimeCompare(got, key) == 1
+	}
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain)) == nil
 }
 
-// store only hashes of refresh/reset tokens in DB
-func HashToken(token string) string {
-	sum := sha256.Sum256([]byte(token))
-	return hex.EncodeToString(sum[:])
+// NeedsRehash reports whether hash uses another algorithm or weaker
+// parameters than currently configured.
+func NeedsRehash(hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		if hashCfg.Algorithm != HashAlgoArgon2id {
+			return true
+		}
+		p, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return true
+		}
+		return p.Argon2Time != hashCfg.Argon2Time ||
+			p.Argon2MemKB != hashCfg.Argon2MemKB ||
+			p.Argon2Par != hashCfg.Argon2Par ||
+			uint32(len(salt)) != hashCfg.Argon2Salt ||
+			uint32(len(key)) != hashCfg.Argon2Key
+	}
+
+	if hashCfg.Algorithm != HashAlgoBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != hashCfg.BcryptCost
+}
+
+// hashArgon2id encodes as $argon2id$v=19$m=<kb>,t=<time>,p=<par>$<salt>$<key>
+func hashArgon2id(plain string, c PasswordHashConfig) (string, error) {
+	salt := make([]byte, c.Argon2Salt)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(plain), salt, c.Argon2Time, c.Argon2MemKB, c.Argon2Par, c.Argon2Key)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, c.Argon2MemKB, c.Argon2Time, c.Argon2Par,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func decodeArgon2id(hash string) (PasswordHashConfig, []byte, []byte, error) {
+	var p PasswordHashConfig
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errors.New("invalid argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errors.New("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Argon2MemKB, &p.Argon2Time, &p.Argon2Par); err != nil {
+		return p, nil, nil, err
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	p.Algorithm = HashAlgoArgon2id
+	return p, salt, key, nil
 }
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"ecommerce/internal/config"
+)
+
+// minPasswordLength is the floor PASSWORD_MIN_LENGTH can't go below
+const minPasswordLength = 8
+
+// PasswordPolicy is applied wherever a password is set (register, reset,
+// change, admin CLI).
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	DisallowEmail bool
+
+	breached *BreachedList
+}
+
+func NewPasswordPolicy(cfg config.Config) (*PasswordPolicy, error) {
+	p := &PasswordPolicy{
+		MinLength:     max(cfg.PasswordMinLength, minPasswordLength),
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+		DisallowEmail: true,
+	}
+	list, err := parseBreachedList(strings.NewReader(bundledBreached))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PasswordBreachedList != "" {
+		if list, err = LoadBreachedList(cfg.PasswordBreachedList); err != nil {
+			return nil, err
+		}
+	}
+	p.breached = list
+	return p, nil
+}
+
+// Validate returns one human readable message per violated rule
+func (p *PasswordPolicy) Validate(password, email string) []string {
+	var out []string
+
+	if n := len([]rune(password)); n < p.MinLength {
+		out = append(out, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		out = append(out, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		out = append(out, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		out = append(out, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		out = append(out, "must contain a symbol")
+	}
+
+	if p.DisallowEmail && email != "" {
+		lowerPw := strings.ToLower(password)
+		local, _, _ := strings.Cut(strings.ToLower(email), "@")
+		if strings.Contains(lowerPw, strings.ToLower(email)) || (len(local) >= 3 && strings.Contains(lowerPw, local)) {
+			out = append(out, "must not contain your email address")
+		}
+	}
+
+	if p.breached != nil && p.breached.Contains(password) {
+		out = append(out, "has appeared in a data breach, choose a different one")
+	}
+	return out
+}
+
+// BreachedList holds SHA-1 hashes of breached passwords grouped by their
+// 5 character prefix, the same k-anonymity layout as the HIBP range API.
+type BreachedList struct {
+	ranges map[string]map[string]struct{}
+}
+
+// bundledBreached is the list built into the binary, used unless
+// PASSWORD_BREACHED_LIST points at a (larger) file
+//
+//go:embed breached_sha1.txt
+var bundledBreached string
+
+// LoadBreachedList reads a file of "SHA1HEX[:count]" lines (# comments allowed)
+func LoadBreachedList(path string) (*BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("breached password list: %w", err)
+	}
+	defer f.Close()
+	return parseBreachedList(f)
+}
+
+func parseBreachedList(r io.Reader) (*BreachedList, error) {
+	l := &BreachedList{ranges: map[string]map[string]struct{}{}}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != 40 {
+			continue
+		}
+		prefix, suffix := hash[:5], hash[5:]
+		if l.ranges[prefix] == nil {
+			l.ranges[prefix] = map[string]struct{}{}
+		}
+		l.ranges[prefix][suffix] = struct{}{}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("breached password list: %w", err)
+	}
+	return l, nil
+}
+
+func (l *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	_, ok := l.ranges[hash[:5]][hash[5:]]
+	return ok
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+
+	"ecommerce/internal/config"
+)
+
+func TestNewPasswordPolicyBreachedList(t *testing.T) {
+	// works from any working directory: the list is built in
+	p, err := NewPasswordPolicy(config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.breached == nil || !p.breached.Contains("password") || p.breached.Contains("a-much-longer unusual passphrase") {
+		t.Error("bundled breached list not loaded")
+	}
+
+	// an override the operator chose must load, not fail open
+	if _, err := NewPasswordPolicy(config.Config{PasswordBreachedList: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Error("missing override: expected an error")
+	}
+}
+
+func TestNewPasswordPolicyMinLengthFloor(t *testing.T) {
+	for _, n := range []int{-1, 0, 4} {
+		p, err := NewPasswordPolicy(config.Config{PasswordMinLength: n})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.MinLength != minPasswordLength || len(p.Validate("Ab1xyz", "")) == 0 {
+			t.Errorf("PASSWORD_MIN_LENGTH=%d: min length %d, want %d", n, p.MinLength, minPasswordLength)
+		}
+	}
+	p, err := NewPasswordPolicy(config.Config{PasswordMinLength: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MinLength != 12 {
+		t.Errorf("min length %d, want 12", p.MinLength)
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"testing"
+
+	"ecommerce/internal/config"
+)
+
+func TestConfigurePasswordHashingRejectsBadSettings(t *testing.T) {
+	valid := config.Config{PasswordHashAlgo: HashAlgoArgon2id, BcryptCost: 10, Argon2Time: 2, Argon2MemoryKB: 19 * 1024, Argon2Parallelism: 1}
+	if err := ConfigurePasswordHashing(valid); err != nil {
+		t.Fatalf("valid settings: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *config.Config)
+	}{
+		{"unknown algorithm", func(c *config.Config) { c.PasswordHashAlgo = "argon2" }},
+		{"zero argon2 time", func(c *config.Config) { c.Argon2Time = 0 }},
+		{"zero parallelism", func(c *config.Config) { c.Argon2Parallelism = 0 }},
+		{"parallelism over 255", func(c *config.Config) { c.Argon2Parallelism = 256 }},
+		{"too little memory", func(c *config.Config) { c.Argon2MemoryKB = 4 }},
+		{"bcrypt cost too high", func(c *config.Config) { c.PasswordHashAlgo = HashAlgoBcrypt; c.BcryptCost = 40 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			if err := ConfigurePasswordHashing(c); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	// rejected settings leave the valid ones in place
+	hash, err := HashPassword("Secret123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CheckPassword(hash, "Secret123") || NeedsRehash(hash) {
+		t.Errorf("hash %q does not match the configured settings", hash)
+	}
+}
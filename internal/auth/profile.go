@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/domain/user"
+	"ecommerce/internal/util"
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,19}$`)
+
+type updateProfileReq struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	Phone       *string `json:"phone"`
+}
+
+// Sensitive changes need current_password, or for accounts created by a
+// social login (whose password nobody knows) an otp from RequestReauth.
+type changePasswordReq struct {
+	CurrentPassword string `json:"current_password"`
+	OTP             string `json:"otp"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type changeEmailReq struct {
+	NewEmail        string `json:"new_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password"`
+	OTP             string `json:"otp"`
+}
+
+type confirmEmailReq struct {
+	OTP string `json:"otp" binding:"required,len=6"`
+}
+
+type deleteAccountReq struct {
+	CurrentPassword string `json:"current_password"`
+	OTP             string `json:"otp"`
+}
+
+// RequestReauth emails a one-time code that stands in for current_password.
+// Only accounts with a linked sign-in provider can use it; everyone else
+// knows their password (or can reset it).
+func (h *Handler) RequestReauth(c *gin.Context) {
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	idents, err := h.deps.Identities.ListByUser(u.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "identity lookup failed"})
+		return
+	}
+	if len(idents) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "use your current password"})
+		return
+	}
+
+	otp, exp, err := h.issueOTP(u.ID, OTPPurposeReauth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue otp"})
+		return
+	}
+	_ = h.sendOTPEmail(u.Email, otp, exp, "Confirm it's you")
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "message": "OTP sent to your email address."})
+}
+
+// reauthenticated checks the current password or a re-auth OTP and
+// responds 400/401 (or 429 while locked) when neither is valid. Failures
+// count towards the account lockout so the 6-digit code can't be guessed.
+func (h *Handler) reauthenticated(c *gin.Context, u user.User, password, otp string) bool {
+	if password == "" && otp == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "current_password or otp is required"})
+		return false
+	}
+	if h.accountLocked(c, u) {
+		return false
+	}
+
+	if password != "" {
+		if !CheckPassword(u.PasswordHash, password) {
+			h.recordLoginFailure(c, &u)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "current password is incorrect"})
+			return false
+		}
+		return true
+	}
+
+	valid, err := h.verifyOTP(u.ID, OTPPurposeReauth, strings.TrimSpace(otp))
+	if err != nil || !valid {
+		h.recordLoginFailure(c, &u)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired otp"})
+		return false
+	}
+	_ = h.deps.OTP.Delete(u.ID, OTPPurposeReauth)
+	return true
+}
+
+// PATCH /me: display name and phone
+func (h *Handler) UpdateMe(c *gin.Context) {
+	var req updateProfileReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Phone != nil && *req.Phone != "" && !phonePattern.MatchString(strings.TrimSpace(*req.Phone)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid phone number"})
+		return
+	}
+
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if err := h.deps.Users.UpdateProfile(u.ID, req.DisplayName, req.Phone); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "profile update failed"})
+		return
+	}
+
+	u, _ = h.deps.Users.ByID(u.ID)
+	c.JSON(http.StatusOK, sanitizeUser(u))
+}
+
+// Change password (requires the current one or a re-auth code); all other sessions are
+// revoked and the caller gets a fresh token pair.
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req changePasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if !h.reauthenticated(c, u, req.CurrentPassword, req.OTP) {
+		return
+	}
+	if !h.passwordAllowed(c, req.NewPassword, u.Email) {
+		return
+	}
+
+	newHash, err := HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hash failed"})
+		return
+	}
+	if err := h.deps.Users.UpdatePassword(u.ID, newHash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password update failed"})
+		return
+	}
+	if err := h.deps.Refresh.RevokeAllForUser(u.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+	_ = h.sendMailSafe(u.Email, "Your password was changed",
+		"The password of your account was just changed. If this wasn't you, reset your password immediately.")
+
+	h.respondWithTokens(c, u, c.GetBool(CtxMFAKey))
+}
+
+// Start an email change: OTP goes to the new address
+func (h *Handler) RequestEmailChange(c *gin.Context) {
+	var req changeEmailReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	newEmail := strings.TrimSpace(strings.ToLower(req.NewEmail))
+
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if !h.reauthenticated(c, u, req.CurrentPassword, req.OTP) {
+		return
+	}
+	if newEmail == u.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "new email is the same as the current one"})
+		return
+	}
+	if _, err := h.deps.Users.ByEmail(newEmail); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "email already exists"})
+		return
+	}
+
+	if err := h.deps.Users.SetPendingEmail(u.ID, newEmail); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "email change failed"})
+		return
+	}
+	otp, exp, err := h.issueOTP(u.ID, OTPPurposeChangeEmail)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue otp"})
+		return
+	}
+	_ = h.sendOTPEmail(newEmail, otp, exp, "Confirm your new email")
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "message": "OTP sent to the new email address."})
+}
+
+// Finish an email change with the OTP sent to the new address
+func (h *Handler) ConfirmEmailChange(c *gin.Context) {
+	var req confirmEmailReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if u.PendingEmail == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no email change pending"})
+		return
+	}
+
+	if h.accountLocked(c, u) {
+		return
+	}
+
+	valid, err := h.verifyOTP(u.ID, OTPPurposeChangeEmail, req.OTP)
+	if err != nil || !valid {
+		h.recordLoginFailure(c, &u)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired otp"})
+		return
+	}
+	if err := h.deps.Users.ConfirmEmailChange(u.ID, *u.PendingEmail); err != nil {
+		if errors.Is(err, ErrEmailTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "email already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "email change failed"})
+		return
+	}
+	_ = h.deps.OTP.Delete(u.ID, OTPPurposeChangeEmail)
+	_ = h.sendMailSafe(u.Email, "Your email was changed",
+		"The email of your account was changed to "+*u.PendingEmail+". If this wasn't you, contact support.")
+
+	u, _ = h.deps.Users.ByID(u.ID)
+	c.JSON(http.StatusOK, sanitizeUser(u))
+}
+
+// DELETE /me: anonymize personal data (right to erasure). The user row is
+// kept so order history and audit entries still reference a valid id.
+func (h *Handler) DeleteMe(c *gin.Context) {
+	var req deleteAccountReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	u, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if !h.reauthenticated(c, u, req.CurrentPassword, req.OTP) {
+		return
+	}
+
+	pw, err := util.RandomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "account deletion failed"})
+		return
+	}
+	unusable, err := HashPassword(pw)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "account deletion failed"})
+		return
+	}
+	exportFiles, err := h.deps.Users.Anonymize(u.ID, unusable)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "account deletion failed"})
+		return
+	}
+	for _, f := range exportFiles {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			log.Printf("delete user %d: remove export %s: %v", u.ID, f, err)
+		}
+	}
+	_ = h.sendMailSafe(u.Email, "Your account was deleted",
+		"Your account and its personal data have been deleted.")
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
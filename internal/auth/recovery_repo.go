@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCode is the GORM model for user_recovery_codes table
+type RecoveryCode struct {
+	ID        int64      `gorm:"primaryKey;autoIncrement"`
+	UserID    int64      `gorm:"not null;index"`
+	CodeHash  string     `gorm:"column:code_hash;not null"`
+	UsedAt    *time.Time `gorm:""`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+func (RecoveryCode) TableName() string { return "user_recovery_codes" }
+
+type RecoveryRepo struct {
+	db *gorm.DB
+}
+
+func NewRecoveryRepo(db *gorm.DB) *RecoveryRepo {
+	return &RecoveryRepo{db: db}
+}
+
+// Replace drops all existing codes of the user and stores the new hashes
+func (r *RecoveryRepo) Replace(userID int64, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]RecoveryCode, 0, len(codeHashes))
+		for _, h := range codeHashes {
+			codes = append(codes, RecoveryCode{UserID: userID, CodeHash: h})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Consume marks an unused code as used; returns false if no such code
+func (r *RecoveryRepo) Consume(userID int64, codeHash string) (bool, error) {
+	res := r.db.Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *RecoveryRepo) CountUnused(userID int64) (int64, error) {
+	var n int64
+	err := r.db.Model(&RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *RecoveryRepo) DeleteAll(userID int64) error {
+	return r.db.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error
+}
@@ -50,3 +50,11 @@ func (r *RefreshRepo) Revoke(userID int64, tokenHash string) error {
 		Where("user_id = ? AND token_hash = ? AND revoked_at IS NULL", userID, tokenHash).
 		Update("revoked_at", now).Error
 }
+
+// RevokeAllForUser ends every session of the user (e.g. on deactivation)
+func (r *RefreshRepo) RevokeAllForUser(userID int64) error {
+	now := time.Now()
+	return r.db.Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"ecommerce/internal/domain/role"
+)
+
+var (
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrRoleNotFound      = errors.New("role not found")
+	// built-in roles are managed by migrations, so an admin can't strip
+	// admin:access from "admin" and lock everyone out
+	ErrSystemRole = errors.New("built-in roles cannot be changed")
+)
+
+type RoleRepo struct {
+	db *gorm.DB
+}
+
+func NewRoleRepo(db *gorm.DB) *RoleRepo {
+	return &RoleRepo{db: db}
+}
+
+// PermissionsFor resolves the permission codes granted to a role
+func (r *RoleRepo) PermissionsFor(roleName string) ([]string, error) {
+	var codes []string
+	err := r.db.Table("role_permissions rp").
+		Select("p.code").
+		Joins("JOIN roles ro ON ro.id = rp.role_id").
+		Joins("JOIN permissions p ON p.id = rp.permission_id").
+		Where("ro.name = ?", roleName).
+		Order("p.code ASC").
+		Pluck("p.code", &codes).Error
+	return codes, err
+}
+
+func (r *RoleRepo) ByName(name string) (role.Role, error) {
+	var ro role.Role
+	if err := r.db.Where("name = ?", name).First(&ro).Error; err != nil {
+		return role.Role{}, err
+	}
+	perms, err := r.PermissionsFor(ro.Name)
+	if err != nil {
+		return role.Role{}, err
+	}
+	ro.Permissions = perms
+	return ro, nil
+}
+
+func (r *RoleRepo) List() ([]role.Role, error) {
+	var out []role.Role
+	if err := r.db.Order("name ASC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	for i := range out {
+		perms, err := r.PermissionsFor(out[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		out[i].Permissions = perms
+	}
+	return out, nil
+}
+
+func (r *RoleRepo) ListPermissions() ([]role.Permission, error) {
+	var out []role.Permission
+	err := r.db.Order("code ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *RoleRepo) Create(name, description string, perms []string) (role.Role, error) {
+	ro := role.Role{Name: name, Description: description}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ro).Error; err != nil {
+			return err
+		}
+		return setRolePermissions(tx, ro.ID, perms)
+	})
+	if err != nil {
+		return role.Role{}, err
+	}
+	return r.ByName(name)
+}
+
+// SetPermissions replaces the permission set of a custom role
+func (r *RoleRepo) SetPermissions(name string, perms []string) (role.Role, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var ro role.Role
+		err := tx.Where("name = ?", name).First(&ro).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if ro.IsSystem {
+			return ErrSystemRole
+		}
+		return setRolePermissions(tx, ro.ID, perms)
+	})
+	if err != nil {
+		return role.Role{}, err
+	}
+	return r.ByName(name)
+}
+
+// Delete removes a custom role; fails for system roles or roles still assigned
+func (r *RoleRepo) Delete(name string) error {
+	var ro role.Role
+	err := r.db.Where("name = ?", name).First(&ro).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRoleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if ro.IsSystem {
+		return ErrSystemRole
+	}
+	return r.db.Where("id = ? AND is_system = ?", ro.ID, false).Delete(&role.Role{}).Error
+}
+
+func setRolePermissions(tx *gorm.DB, roleID int64, codes []string) error {
+	var perms []role.Permission
+	if len(codes) > 0 {
+		if err := tx.Where("code IN ?", codes).Find(&perms).Error; err != nil {
+			return err
+		}
+		if len(perms) != len(uniqueStrings(codes)) {
+			return ErrUnknownPermission
+		}
+	}
+
+	if err := tx.Where("role_id = ?", roleID).Delete(&role.RolePermission{}).Error; err != nil {
+		return err
+	}
+	for _, p := range perms {
+		if err := tx.Create(&role.RolePermission{RoleID: roleID, PermissionID: p.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func uniqueStrings(in []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
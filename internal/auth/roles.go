@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createRoleReq struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type setPermissionsReq struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type assignRoleReq struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// Admin: roles with their permissions
+func (h *Handler) AdminListRoles(c *gin.Context) {
+	roles, err := h.deps.Roles.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list roles"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": roles})
+}
+
+// Admin: all known permission codes
+func (h *Handler) AdminListPermissions(c *gin.Context) {
+	perms, err := h.deps.Roles.ListPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list permissions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": perms})
+}
+
+func (h *Handler) AdminCreateRole(c *gin.Context) {
+	var req createRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	name := strings.TrimSpace(strings.ToLower(req.Name))
+
+	ro, err := h.deps.Roles.Create(name, req.Description, req.Permissions)
+	if errors.Is(err, ErrUnknownPermission) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "failed to create role (name may be duplicate)"})
+		return
+	}
+	c.JSON(http.StatusCreated, ro)
+}
+
+func (h *Handler) AdminSetRolePermissions(c *gin.Context) {
+	var req setPermissionsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	ro, err := h.deps.Roles.SetPermissions(c.Param("name"), req.Permissions)
+	switch {
+	case errors.Is(err, ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+		return
+	case errors.Is(err, ErrSystemRole):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update role"})
+		return
+	}
+	c.JSON(http.StatusOK, ro)
+}
+
+func (h *Handler) AdminDeleteRole(c *gin.Context) {
+	err := h.deps.Roles.Delete(c.Param("name"))
+	switch {
+	case errors.Is(err, ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+		return
+	case errors.Is(err, ErrSystemRole):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to delete role (still assigned)"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// Admin: assign a role to a user. Takes effect on the user's next token
+// refresh; existing access tokens keep their permissions until they expire.
+func (h *Handler) AdminAssignRole(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req assignRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if _, err := h.deps.Roles.ByName(req.Role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown role"})
+		return
+	}
+
+	u, err := h.deps.Users.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if u.ID == c.GetInt64(CtxUserIDKey) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot change your own status or role"})
+		return
+	}
+	if err := h.deps.Users.SetRole(id, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+		return
+	}
+	h.audit(c, "user.role_change", u.ID, gin.H{"from": u.Role, "to": req.Role})
+
+	u, _ = h.deps.Users.ByID(id)
+	c.JSON(http.StatusOK, sanitizeUser(u))
+}
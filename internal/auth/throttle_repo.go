@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// failures older than this no longer count towards a lockout
+const throttleFailureWindow = 15 * time.Minute
+
+// LoginThrottle is the GORM model for login_throttles table
+type LoginThrottle struct {
+	Key          string     `json:"key" gorm:"primaryKey"`
+	UserID       *int64     `json:"user_id,omitempty" gorm:"index"`
+	FailCount    int        `json:"fail_count" gorm:"not null;default:0"`
+	LockoutCount int        `json:"lockout_count" gorm:"not null;default:0"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+	LastFailedAt *time.Time `json:"last_failed_at,omitempty"`
+	CreatedAt    time.Time  `json:"-" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"-" gorm:"autoUpdateTime"`
+}
+
+func (LoginThrottle) TableName() string { return "login_throttles" }
+
+// LockoutPolicy controls when and for how long a key gets locked
+type LockoutPolicy struct {
+	MaxFailures int
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+// duration doubles with every lockout: base, 2*base, 4*base ... capped
+func (p LockoutPolicy) duration(lockoutCount int) time.Duration {
+	d := p.BaseLockout
+	for i := 1; i < lockoutCount && d < p.MaxLockout; i++ {
+		d *= 2
+	}
+	if d > p.MaxLockout {
+		d = p.MaxLockout
+	}
+	return d
+}
+
+type ThrottleRepo struct {
+	db *gorm.DB
+}
+
+func NewThrottleRepo(db *gorm.DB) *ThrottleRepo {
+	return &ThrottleRepo{db: db}
+}
+
+// LockedUntil returns the lock expiry if key is currently locked
+func (r *ThrottleRepo) LockedUntil(key string) (time.Time, bool) {
+	var t LoginThrottle
+	err := r.db.Where("key = ? AND locked_until > ?", key, time.Now()).First(&t).Error
+	if err != nil || t.LockedUntil == nil {
+		return time.Time{}, false
+	}
+	return *t.LockedUntil, true
+}
+
+// RecordFailure counts a failed attempt and locks the key once the policy
+// threshold is reached. Returns the lock expiry when a new lock was set.
+func (r *ThrottleRepo) RecordFailure(key string, userID *int64, p LockoutPolicy) (time.Time, bool, error) {
+	var lockedUntil time.Time
+	var locked bool
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&LoginThrottle{Key: key, UserID: userID}).Error; err != nil {
+			return err
+		}
+
+		var t LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).First(&t).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if t.LastFailedAt != nil && now.Sub(*t.LastFailedAt) > throttleFailureWindow {
+			t.FailCount = 0
+		}
+		t.FailCount++
+		t.LastFailedAt = &now
+
+		if p.MaxFailures > 0 && t.FailCount >= p.MaxFailures {
+			t.LockoutCount++
+			until := now.Add(p.duration(t.LockoutCount))
+			t.LockedUntil = &until
+			t.FailCount = 0
+			lockedUntil, locked = until, true
+		}
+
+		return tx.Model(&LoginThrottle{}).Where("key = ?", key).Updates(map[string]interface{}{
+			"fail_count":     t.FailCount,
+			"lockout_count":  t.LockoutCount,
+			"locked_until":   t.LockedUntil,
+			"last_failed_at": t.LastFailedAt,
+		}).Error
+	})
+	return lockedUntil, locked, err
+}
+
+// Reset forgets failures and backoff history (successful login / admin unlock)
+func (r *ThrottleRepo) Reset(key string) error {
+	return r.db.Where("key = ?", key).Delete(&LoginThrottle{}).Error
+}
+
+// LockedAccount is a currently locked user, for the admin view
+type LockedAccount struct {
+	UserID       int64      `json:"user_id"`
+	Email        string     `json:"email"`
+	LockedUntil  time.Time  `json:"locked_until"`
+	LockoutCount int        `json:"lockout_count"`
+	LastFailedAt *time.Time `json:"last_failed_at,omitempty"`
+}
+
+func (r *ThrottleRepo) ListLockedAccounts() ([]LockedAccount, error) {
+	var out []LockedAccount
+	err := r.db.Table("login_throttles t").
+		Select("u.id as user_id, u.email, t.locked_until, t.lockout_count, t.last_failed_at").
+		Joins("JOIN users u ON u.id = t.user_id").
+		Where("t.locked_until > ?", time.Now()).
+		Order("t.locked_until DESC").
+		Scan(&out).Error
+	return out, err
+}
+
+func (r *ThrottleRepo) ListLockedIPs() ([]LoginThrottle, error) {
+	var out []LoginThrottle
+	err := r.db.Where("user_id IS NULL AND locked_until > ?", time.Now()).
+		Order("locked_until DESC").
+		Find(&out).Error
+	return out, err
+}
@@ -2,12 +2,19 @@ package auth
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
+	"ecommerce/internal/db"
 	"ecommerce/internal/domain/user"
 )
 
+// ErrEmailTaken is returned when another account already uses the address.
+var ErrEmailTaken = errors.New("email already exists")
+
 type UserRepo struct {
 	db *gorm.DB
 }
@@ -62,3 +69,180 @@ func (r *UserRepo) UpdatePassword(userID int64, newHash string) error {
 func (r *UserRepo) SetEmailVerified(userID int64) error {
 	return r.db.Model(&user.User{}).Where("id = ?", userID).Update("email_verified", true).Error
 }
+
+// SetTOTPSecret stores a pending secret; 2FA stays off until EnableTOTP
+func (r *UserRepo) SetTOTPSecret(userID int64, secret string) error {
+	return r.db.Model(&user.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"totp_secret":    secret,
+			"totp_enabled":   false,
+			"totp_last_step": nil,
+		}).Error
+}
+
+func (r *UserRepo) EnableTOTP(userID int64) error {
+	return r.db.Model(&user.User{}).Where("id = ?", userID).Update("totp_enabled", true).Error
+}
+
+func (r *UserRepo) DisableTOTP(userID int64) error {
+	return r.db.Model(&user.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"totp_secret":    nil,
+			"totp_enabled":   false,
+			"totp_last_step": nil,
+		}).Error
+}
+
+// UseTOTPStep records the last accepted step; returns false if the
+// step was already used (replayed code)
+func (r *UserRepo) UseTOTPStep(userID int64, step int64) (bool, error) {
+	res := r.db.Model(&user.User{}).
+		Where("id = ? AND (totp_last_step IS NULL OR totp_last_step < ?)", userID, step).
+		Update("totp_last_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *UserRepo) SetRole(userID int64, role string) error {
+	result := r.db.Model(&user.User{}).Where("id = ?", userID).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// List returns users (newest first) optionally filtered by email substring
+func (r *UserRepo) List(emailQuery string, limit, offset int) ([]user.User, int64, error) {
+	q := r.db.Model(&user.User{})
+	if emailQuery != "" {
+		q = q.Where("email ILIKE ?", "%"+escapeLike(emailQuery)+"%")
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var out []user.User
+	err := q.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&out).Error
+	return out, total, err
+}
+
+func (r *UserRepo) SetActive(userID int64, active bool) error {
+	return r.db.Model(&user.User{}).Where("id = ?", userID).Update("is_active", active).Error
+}
+
+func (r *UserRepo) SetEmailVerifiedTo(userID int64, verified bool) error {
+	return r.db.Model(&user.User{}).Where("id = ?", userID).Update("email_verified", verified).Error
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *UserRepo) ListByRole(role string) ([]user.User, error) {
+	var out []user.User
+	err := r.db.Where("role = ?", role).Order("id ASC").Find(&out).Error
+	return out, err
+}
+
+// UpdateProfile changes only the non-nil fields (empty string clears)
+func (r *UserRepo) UpdateProfile(userID int64, displayName, phone *string) error {
+	updates := map[string]interface{}{}
+	if displayName != nil {
+		updates["display_name"] = nullIfEmpty(*displayName)
+	}
+	if phone != nil {
+		updates["phone"] = nullIfEmpty(*phone)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return r.db.Model(&user.User{}).Where("id = ?", userID).Updates(updates).Error
+}
+
+func (r *UserRepo) SetPendingEmail(userID int64, email string) error {
+	return r.db.Model(&user.User{}).Where("id = ?", userID).Update("pending_email", email).Error
+}
+
+// ConfirmEmailChange swaps in the pending address (fails if it was taken meanwhile)
+func (r *UserRepo) ConfirmEmailChange(userID int64, newEmail string) error {
+	err := r.db.Model(&user.User{}).Where("id = ? AND pending_email = ?", userID, newEmail).
+		Updates(map[string]interface{}{
+			"email":          newEmail,
+			"pending_email":  nil,
+			"email_verified": true,
+		}).Error
+	if db.IsUniqueViolation(err) {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// Anonymize erases personal data but keeps the row so anything referencing
+// the user (orders, audit log) stays consistent. It returns the data export
+// archives whose rows it deleted; the caller removes the files once this
+// has committed.
+func (r *UserRepo) Anonymize(userID int64, unusableHash string) ([]string, error) {
+	var exportFiles []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var email string
+		if err := tx.Model(&user.User{}).Select("email").Where("id = ?", userID).Scan(&email).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		err := tx.Model(&user.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"email":          fmt.Sprintf("deleted-%d@deleted.invalid", userID),
+			"password_hash":  unusableHash,
+			"display_name":   nil,
+			"phone":          nil,
+			"pending_email":  nil,
+			"is_active":      false,
+			"email_verified": false,
+			"totp_secret":    nil,
+			"totp_enabled":   false,
+			"totp_last_step": nil,
+			"deleted_at":     now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// personal records that have no value once the account is gone
+		for _, table := range []string{
+			"refresh_tokens", "user_otps", "password_resets", "user_recovery_codes",
+			"user_identities", "user_known_devices", "login_throttles", "carts",
+			"wishlists", "stock_notifications", "addresses",
+		} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE user_id = ?", userID).Error; err != nil {
+				return err
+			}
+		}
+		// back-in-stock alerts signed up for as a guest with the same address
+		if err := tx.Exec("DELETE FROM stock_notifications WHERE email = ?", email).Error; err != nil {
+			return err
+		}
+
+		// data exports hold a full copy of the above
+		err = tx.Table("data_exports").Where("user_id = ? AND file_path <> ''", userID).
+			Pluck("file_path", &exportFiles).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM data_exports WHERE user_id = ?", userID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return exportFiles, nil
+}
+
+func nullIfEmpty(s string) interface{} {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,73 @@
+package cart
+
+import (
+	"time"
+
+	"ecommerce/internal/coupons"
+	cartDomain "ecommerce/internal/domain/cart"
+	"ecommerce/internal/domain/coupon"
+)
+
+// applyCoupon evaluates the cart's coupon against the purchasable lines
+// (after promotions) and adds its discount to the totals. A coupon that no
+// longer applies stays on the cart with the reason, so the customer can see
+// why.
+func (r *Repo) applyCoupon(crt *cartDomain.Cart) error {
+	if crt.CouponID == nil {
+		return nil
+	}
+	cp, err := r.coupons.ByID(*crt.CouponID)
+	if err != nil {
+		return err
+	}
+	res, evalErr := r.evaluateCoupon(*crt, cp)
+
+	crt.Coupon = &cartDomain.AppliedCoupon{Code: cp.Code, Kind: cp.Kind}
+	if evalErr != nil {
+		crt.Coupon.Error = evalErr.Error()
+		return nil
+	}
+	crt.Coupon.Discount = res.Discount
+	crt.Coupon.FreeShipping = res.FreeShipping
+
+	crt.Totals.CouponDiscount = res.Discount
+	crt.Totals.FreeShipping = res.FreeShipping
+	crt.Totals.GrandTotal = round2(crt.Totals.GrandTotal - res.Discount)
+	return nil
+}
+
+func (r *Repo) evaluateCoupon(crt cartDomain.Cart, cp coupon.Coupon) (coupons.Result, error) {
+	var lines []coupons.Line
+	for _, it := range crt.Items {
+		if purchasable(it) {
+			lines = append(lines, coupons.Line{
+				ProductID:  it.ProductID,
+				CategoryID: it.CategoryID,
+				Amount:     round2(it.LineTotal - it.PromotionDiscount),
+			})
+		}
+	}
+	cartValue := round2(crt.Totals.ItemsTotal - crt.Totals.PromotionDiscount)
+	return coupons.Evaluate(cp, lines, cartValue, time.Now())
+}
+
+// SetCoupon attaches the coupon with this code after checking it applies
+// to the cart now. Engine errors (coupons.Err*) explain why not.
+func (r *Repo) SetCoupon(cartID int64, code string) error {
+	cp, err := r.coupons.ByCode(code)
+	if err != nil {
+		return err
+	}
+	crt, err := r.GetCart(cartID)
+	if err != nil {
+		return err
+	}
+	if _, err := r.evaluateCoupon(crt, cp); err != nil {
+		return err
+	}
+	return r.db.Model(&cartDomain.Cart{}).Where("id = ?", cartID).Update("coupon_id", cp.ID).Error
+}
+
+func (r *Repo) RemoveCoupon(cartID int64) error {
+	return r.db.Model(&cartDomain.Cart{}).Where("id = ?", cartID).Update("coupon_id", nil).Error
+}
@@ -1,50 +1,143 @@
 package cart
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"ecommerce/internal/address"
 	"ecommerce/internal/auth"
+	"ecommerce/internal/coupons"
+	cartDomain "ecommerce/internal/domain/cart"
+	"ecommerce/internal/tax"
+	"ecommerce/internal/util"
 )
 
+const (
+	// Guests send their cart token back in this header or cookie
+	CartTokenHeader = "X-Cart-Token"
+	CartTokenCookie = "cart_token"
+
+	cartTokenMaxAge = 30 * 24 * 60 * 60
+)
+
+type Config struct {
+	SecureCookie     bool           // cart token cookie only over HTTPS
+	Tax              tax.Calculator // nil = no tax in totals
+	PricesIncludeTax bool           // catalog prices are gross (tax-inclusive display)
+}
+
 type Handler struct {
 	repo *Repo
+	cfg  Config
 }
 
-func NewHandler(repo *Repo) *Handler {
-	return &Handler{repo: repo}
+func NewHandler(repo *Repo, cfg Config) *Handler {
+	return &Handler{repo: repo, cfg: cfg}
 }
 
 func (h *Handler) GetMyCart(c *gin.Context) {
-	userIDAny, _ := c.Get(auth.CtxUserIDKey)
-	userID := userIDAny.(int64)
+	cartID, ok := h.cartID(c, false)
+	if !ok {
+		return
+	}
+	if cartID == 0 {
+		// guest without a cart yet: nothing to create until an item is added
+		c.JSON(http.StatusOK, cartDomain.Cart{Items: []cartDomain.CartItem{}})
+		return
+	}
 
-	crt, err := h.repo.GetCart(userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load cart"})
+	dest, ok := h.taxDestination(c)
+	if !ok {
+		return
+	}
+	crt, ok := h.loadCart(c, cartID, dest)
+	if !ok {
 		return
 	}
 	c.JSON(http.StatusOK, crt)
 }
 
+// loadCart loads the cart and finishes its totals. Every response that
+// shows cart totals goes through here so they always agree; tax is added
+// for dest (nil = no destination known, no tax).
+func (h *Handler) loadCart(c *gin.Context, cartID int64, dest *tax.Destination) (cartDomain.Cart, bool) {
+	crt, err := h.repo.GetCart(cartID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load cart"})
+		return crt, false
+	}
+	crt.Totals.TaxIncluded = h.cfg.PricesIncludeTax
+	if h.cfg.Tax == nil || dest == nil {
+		return crt, true
+	}
+	if err := applyTax(c.Request.Context(), h.cfg.Tax, &crt, *dest, h.cfg.PricesIncludeTax); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to calculate tax"})
+		return crt, false
+	}
+	return crt, true
+}
+
+// taxDestination picks the request's destination for cart totals: the
+// ?address_id= or ?country=&region= query, else the user's default
+// shipping address, else none (nil).
+func (h *Handler) taxDestination(c *gin.Context) (*tax.Destination, bool) {
+	dest, found, ok := h.queryDestination(c)
+	if !ok || !found {
+		return nil, ok
+	}
+	return &dest, true
+}
+
+func (h *Handler) queryDestination(c *gin.Context) (tax.Destination, bool, bool) {
+	userID := c.GetInt64(auth.CtxUserIDKey)
+	if raw := c.Query("address_id"); raw != "" {
+		id, _ := strconv.ParseInt(raw, 10, 64)
+		a, err := h.repo.addresses.Get(userID, id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "address not found"})
+			return tax.Destination{}, false, false
+		}
+		return tax.Destination{Country: a.Country, Region: a.Region, PostalCode: a.PostalCode}, true, true
+	}
+	if country := strings.ToUpper(strings.TrimSpace(c.Query("country"))); country != "" {
+		if len(country) != 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "country must be an ISO 3166-1 alpha-2 code"})
+			return tax.Destination{}, false, false
+		}
+		region := strings.ToUpper(strings.TrimSpace(c.Query("region")))
+		return tax.Destination{Country: country, Region: region, PostalCode: c.Query("postal_code")}, true, true
+	}
+	if userID != 0 {
+		if a, err := h.repo.addresses.DefaultShipping(userID); err == nil {
+			return tax.Destination{Country: a.Country, Region: a.Region, PostalCode: a.PostalCode}, true, true
+		}
+	}
+	return tax.Destination{}, false, true
+}
+
 type AddItemReq struct {
 	VariantID int64 `json:"variant_id" binding:"required"`
 	Qty       int   `json:"qty" binding:"required"`
 }
 
 func (h *Handler) AddItem(c *gin.Context) {
-	userIDAny, _ := c.Get(auth.CtxUserIDKey)
-	userID := userIDAny.(int64)
-
 	var req AddItemReq
 	if err := c.ShouldBindJSON(&req); err != nil || req.Qty <= 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
 
-	if err := h.repo.AddItem(userID, req.VariantID, req.Qty); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to add item"})
+	cartID, ok := h.cartID(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.AddItem(cartID, req.VariantID, req.Qty); err != nil {
+		RespondItemError(c, err, "failed to add item")
 		return
 	}
 
@@ -57,17 +150,23 @@ type UpdateQtyReq struct {
 }
 
 func (h *Handler) UpdateQty(c *gin.Context) {
-	userIDAny, _ := c.Get(auth.CtxUserIDKey)
-	userID := userIDAny.(int64)
-
 	var req UpdateQtyReq
 	if err := c.ShouldBindJSON(&req); err != nil || req.Qty <= 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
 
-	if err := h.repo.UpdateQty(userID, req.VariantID, req.Qty); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to update qty"})
+	cartID, ok := h.cartID(c, false)
+	if !ok {
+		return
+	}
+	if cartID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cart not found"})
+		return
+	}
+
+	if err := h.repo.UpdateQty(cartID, req.VariantID, req.Qty); err != nil {
+		RespondItemError(c, err, "failed to update qty")
 		return
 	}
 
@@ -79,19 +178,245 @@ type RemoveItemReq struct {
 }
 
 func (h *Handler) RemoveItem(c *gin.Context) {
-	userIDAny, _ := c.Get(auth.CtxUserIDKey)
-	userID := userIDAny.(int64)
-
 	var req RemoveItemReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
 
-	if err := h.repo.RemoveItem(userID, req.VariantID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to remove item"})
+	cartID, ok := h.cartID(c, false)
+	if !ok {
+		return
+	}
+	if cartID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cart not found"})
+		return
+	}
+
+	if err := h.repo.RemoveItem(cartID, req.VariantID); err != nil {
+		RespondItemError(c, err, "failed to remove item")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+type ApplyCouponReq struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// ApplyCoupon attaches a coupon to the cart (replacing any previous one)
+func (h *Handler) ApplyCoupon(c *gin.Context) {
+	var req ApplyCouponReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	cartID, ok := h.cartID(c, false)
+	if !ok {
+		return
+	}
+	if cartID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cart not found"})
+		return
+	}
+
+	err := h.repo.SetCoupon(cartID, strings.TrimSpace(req.Code))
+	switch {
+	case errors.Is(err, coupons.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invalid coupon code"})
+		return
+	case coupons.IsRejection(err):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply coupon"})
+		return
+	}
+
+	dest, ok := h.taxDestination(c)
+	if !ok {
+		return
+	}
+	crt, ok := h.loadCart(c, cartID, dest)
+	if !ok {
 		return
 	}
+	c.JSON(http.StatusOK, crt)
+}
 
+func (h *Handler) RemoveCoupon(c *gin.Context) {
+	cartID, ok := h.cartID(c, false)
+	if !ok {
+		return
+	}
+	if cartID != 0 {
+		if err := h.repo.RemoveCoupon(cartID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove coupon"})
+			return
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
+
+type ShippingQuoteReq struct {
+	AddressID  *int64 `json:"address_id"` // a saved address (logged-in users)
+	Country    string `json:"country"`    // or a destination typed in
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+}
+
+// ShippingQuote returns the shipping methods available for the cart and
+// a destination, priced with the cart's weight, value and coupon. The cart
+// totals for that destination (tax included) come with it.
+func (h *Handler) ShippingQuote(c *gin.Context) {
+	var req ShippingQuoteReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(req.Country))
+	region := strings.ToUpper(strings.TrimSpace(req.Region))
+	postalCode := req.PostalCode
+	if req.AddressID != nil {
+		userID := c.GetInt64(auth.CtxUserIDKey)
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "log in to use a saved address"})
+			return
+		}
+		a, err := h.repo.addresses.Get(userID, *req.AddressID)
+		if err != nil {
+			if errors.Is(err, address.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "address not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load address"})
+			return
+		}
+		country, region, postalCode = a.Country, a.Region, a.PostalCode
+	} else {
+		if len(country) != 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "send address_id or a country code"})
+			return
+		}
+		if req.PostalCode != "" {
+			if _, ok := address.NormalizePostalCode(country, req.PostalCode); !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid postal code for " + country})
+				return
+			}
+		}
+	}
+
+	cartID, ok := h.cartID(c, false)
+	if !ok {
+		return
+	}
+	if cartID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cart is empty"})
+		return
+	}
+	crt, ok := h.loadCart(c, cartID, &tax.Destination{Country: country, Region: region, PostalCode: postalCode})
+	if !ok {
+		return
+	}
+	if crt.Totals.ItemCount == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cart is empty"})
+		return
+	}
+
+	quote, err := h.repo.quoteShipping(crt, country, region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to quote shipping"})
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+// MergeGuestCart folds the request's guest cart into the user's cart.
+// Called by auth on login and register; failures never block the login.
+func (h *Handler) MergeGuestCart(c *gin.Context, userID int64) {
+	token := guestToken(c)
+	if token == "" {
+		return
+	}
+	if err := h.repo.MergeGuestCart(util.HashToken(token), userID); err != nil {
+		return
+	}
+	h.setToken(c, "", -1)
+}
+
+// cartID resolves the cart for the request: the user's cart when logged in,
+// otherwise the guest cart named by the cart token. With create set a guest
+// without a (valid) token gets a new cart and token; without it 0 is returned.
+func (h *Handler) cartID(c *gin.Context, create bool) (int64, bool) {
+	if userID := c.GetInt64(auth.CtxUserIDKey); userID != 0 {
+		id, err := h.repo.UserCartID(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load cart"})
+			return 0, false
+		}
+		return id, true
+	}
+
+	if token := guestToken(c); token != "" {
+		id, found, err := h.repo.FindGuestCartID(util.HashToken(token))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load cart"})
+			return 0, false
+		}
+		if found {
+			return id, true
+		}
+	}
+	if !create {
+		return 0, true
+	}
+
+	token, err := util.RandomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "random failed"})
+		return 0, false
+	}
+	id, err := h.repo.CreateGuestCart(util.HashToken(token))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create cart"})
+		return 0, false
+	}
+	h.setToken(c, token, cartTokenMaxAge)
+	return id, true
+}
+
+func (h *Handler) setToken(c *gin.Context, token string, maxAge int) {
+	if token != "" {
+		c.Header(CartTokenHeader, token)
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(CartTokenCookie, token, maxAge, "/api", "", h.cfg.SecureCookie, true)
+}
+
+// RespondItemError maps cart validation errors to responses (also used by
+// handlers that add to the cart, e.g. wishlist move-to-cart)
+func RespondItemError(c *gin.Context, err error, fallback string) {
+	var stockErr *StockError
+	switch {
+	case errors.As(err, &stockErr):
+		c.JSON(http.StatusConflict, gin.H{"error": "not enough stock", "available": stockErr.Available})
+	case errors.Is(err, ErrVariantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "variant not found"})
+	case errors.Is(err, ErrItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not in cart"})
+	case errors.Is(err, ErrUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": "product unavailable"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fallback})
+	}
+}
+
+func guestToken(c *gin.Context) string {
+	if t := strings.TrimSpace(c.GetHeader(CartTokenHeader)); t != "" {
+		return t
+	}
+	t, _ := c.Cookie(CartTokenCookie)
+	return t
+}
@@ -0,0 +1,48 @@
+package cart
+
+import (
+	"time"
+
+	cartDomain "ecommerce/internal/domain/cart"
+	"ecommerce/internal/promotions"
+)
+
+// applyPromotions runs the promotion engine over the purchasable lines and
+// records the result per line and in the totals. Runs before coupons.
+func (r *Repo) applyPromotions(crt *cartDomain.Cart) error {
+	rules, err := r.promotions.Live(time.Now())
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	var lines []promotions.Line
+	for _, it := range crt.Items {
+		if purchasable(it) {
+			lines = append(lines, promotions.Line{
+				ID:         it.ID,
+				VariantID:  it.VariantID,
+				ProductID:  it.ProductID,
+				CategoryID: it.CategoryID,
+				Qty:        it.Qty,
+				UnitPrice:  it.FinalPrice,
+			})
+		}
+	}
+	res := promotions.Evaluate(rules, lines)
+
+	for i := range crt.Items {
+		it := &crt.Items[i]
+		for _, a := range res.Lines[it.ID] {
+			it.Promotions = append(it.Promotions, cartDomain.AppliedPromotion{
+				PromotionID: a.PromotionID,
+				Name:        a.Name,
+				Units:       a.Units,
+				Discount:    a.Discount,
+			})
+			it.PromotionDiscount = round2(it.PromotionDiscount + a.Discount)
+		}
+	}
+	crt.Totals.PromotionDiscount = res.Discount
+	crt.Totals.GrandTotal = round2(crt.Totals.GrandTotal - res.Discount)
+	return nil
+}
@@ -1,24 +1,52 @@
 package cart
 
 import (
+	"errors"
 	"math"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
+	"ecommerce/internal/address"
+	"ecommerce/internal/coupons"
 	cartDomain "ecommerce/internal/domain/cart"
+	"ecommerce/internal/promotions"
+	"ecommerce/internal/shipping"
 )
 
 type Repo struct {
-	db *gorm.DB
+	db         *gorm.DB
+	coupons    *coupons.Repo
+	promotions *promotions.Repo
+	shipping   *shipping.Repo
+	addresses  *address.Repo
 }
 
 func NewRepo(db *gorm.DB) *Repo {
-	return &Repo{db: db}
+	return &Repo{
+		db:         db,
+		coupons:    coupons.NewRepo(db),
+		promotions: promotions.NewRepo(db),
+		shipping:   shipping.NewRepo(db),
+		addresses:  address.NewRepo(db),
+	}
+}
+
+// WithTx returns a Repo that works inside tx, so a cart change can commit
+// together with another package's writes
+func (r *Repo) WithTx(tx *gorm.DB) *Repo {
+	c := *r
+	c.db = tx
+	return &c
 }
 
-func (r *Repo) getOrCreateCartID(userID int64) (int64, error) {
-	c := cartDomain.Cart{UserID: userID}
+// guestCartTTL is how long an untouched guest cart is kept
+const guestCartTTL = 30 * 24 * time.Hour
+
+// UserCartID returns the user's cart, creating it on first use
+func (r *Repo) UserCartID(userID int64) (int64, error) {
+	c := cartDomain.Cart{UserID: &userID}
 	err := r.db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "user_id"}},
 		DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
@@ -34,65 +62,222 @@ func (r *Repo) getOrCreateCartID(userID int64) (int64, error) {
 	return c.ID, nil
 }
 
-func (r *Repo) AddItem(userID, variantID int64, qty int) error {
-	cartID, err := r.getOrCreateCartID(userID)
+// FindGuestCartID looks up a guest cart by its token hash
+func (r *Repo) FindGuestCartID(tokenHash string) (int64, bool, error) {
+	var c cartDomain.Cart
+	err := r.db.Where("guest_token_hash = ? AND user_id IS NULL", tokenHash).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, nil
+	}
 	if err != nil {
-		return err
+		return 0, false, err
 	}
+	return c.ID, true, nil
+}
+
+// CreateGuestCart creates an empty cart for a freshly issued guest token
+func (r *Repo) CreateGuestCart(tokenHash string) (int64, error) {
+	// opportunistic cleanup; guest carts are cheap to lose
+	_ = r.DeleteStaleGuestCarts(guestCartTTL)
 
-	item := cartDomain.CartItem{
-		CartID:    cartID,
-		VariantID: variantID,
-		Qty:       qty,
+	c := cartDomain.Cart{GuestTokenHash: &tokenHash}
+	if err := r.db.Create(&c).Error; err != nil {
+		return 0, err
 	}
-	return r.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "cart_id"}, {Name: "variant_id"}},
-		DoUpdates: clause.Assignments(map[string]interface{}{
-			"qty": gorm.Expr("cart_items.qty + ?", qty),
-		}),
-	}).Create(&item).Error
+	return c.ID, nil
+}
+
+// DeleteStaleGuestCarts removes guest carts not touched within maxAge
+func (r *Repo) DeleteStaleGuestCarts(maxAge time.Duration) error {
+	return r.db.Where("user_id IS NULL AND updated_at < ?", time.Now().Add(-maxAge)).
+		Delete(&cartDomain.Cart{}).Error
 }
 
-func (r *Repo) UpdateQty(userID, variantID int64, qty int) error {
-	cartID, err := r.getOrCreateCartID(userID)
+// MergeGuestCart moves the guest cart's items into the user's cart, summing
+// quantities per variant and capping them at available stock. The guest cart
+// is deleted afterwards; a missing guest cart is not an error.
+func (r *Repo) MergeGuestCart(tokenHash string, userID int64) error {
+	guestID, found, err := r.FindGuestCartID(tokenHash)
+	if err != nil || !found {
+		return err
+	}
+	userCartID, err := r.UserCartID(userID)
 	if err != nil {
 		return err
 	}
 
-	return r.db.Model(&cartDomain.CartItem{}).
-		Where("cart_id = ? AND variant_id = ?", cartID, variantID).
-		Update("qty", qty).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO cart_items (cart_id, variant_id, qty, added_price)
+			SELECT ?, g.variant_id, LEAST(g.qty, v.stock_qty), g.added_price
+			FROM cart_items g
+			JOIN product_variants v ON v.id = g.variant_id
+			JOIN products p ON p.id = v.product_id
+			JOIN categories c ON c.id = p.category_id
+			WHERE g.cart_id = ? AND v.stock_qty > 0 AND p.is_active AND c.is_active
+			ON CONFLICT (cart_id, variant_id) DO UPDATE
+			SET qty = LEAST(
+				cart_items.qty + EXCLUDED.qty,
+				(SELECT stock_qty FROM product_variants WHERE id = EXCLUDED.variant_id)
+			)`, userCartID, guestID).Error
+		if err != nil {
+			return err
+		}
+		// keep the guest's coupon unless the user already has one
+		if err := tx.Exec(`UPDATE carts SET coupon_id = COALESCE(coupon_id, (SELECT coupon_id FROM carts WHERE id = ?))
+			WHERE id = ?`, guestID, userCartID).Error; err != nil {
+			return err
+		}
+		if err := touch(tx, userCartID); err != nil {
+			return err
+		}
+		return tx.Delete(&cartDomain.Cart{}, guestID).Error
+	})
+}
+
+// AddItem adds qty of a variant to the cart. The variant must exist, be
+// purchasable and have enough stock for the resulting line quantity.
+func (r *Repo) AddItem(cartID, variantID int64, qty int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCart(tx, cartID); err != nil {
+			return err
+		}
+		v, err := loadVariantState(tx, variantID)
+		if err != nil {
+			return err
+		}
+
+		var current int
+		if err := tx.Model(&cartDomain.CartItem{}).
+			Select("COALESCE(MAX(qty), 0)").
+			Where("cart_id = ? AND variant_id = ?", cartID, variantID).
+			Scan(&current).Error; err != nil {
+			return err
+		}
+		if err := v.check(current + qty); err != nil {
+			return err
+		}
+
+		item := cartDomain.CartItem{
+			CartID:     cartID,
+			VariantID:  variantID,
+			Qty:        qty,
+			AddedPrice: &v.FinalPrice,
+		}
+		// topping up a line re-confirms the current price
+		err = tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "cart_id"}, {Name: "variant_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"qty":         gorm.Expr("cart_items.qty + ?", qty),
+				"added_price": v.FinalPrice,
+			}),
+		}).Create(&item).Error
+		if err != nil {
+			return err
+		}
+		return touch(tx, cartID)
+	})
 }
 
-func (r *Repo) RemoveItem(userID, variantID int64) error {
-	cartID, err := r.getOrCreateCartID(userID)
-	if err != nil {
-		return err
+// UpdateQty sets the quantity of an existing line after checking stock
+func (r *Repo) UpdateQty(cartID, variantID int64, qty int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCart(tx, cartID); err != nil {
+			return err
+		}
+		v, err := loadVariantState(tx, variantID)
+		if err != nil {
+			return err
+		}
+		if err := v.check(qty); err != nil {
+			return err
+		}
+
+		res := tx.Model(&cartDomain.CartItem{}).
+			Where("cart_id = ? AND variant_id = ?", cartID, variantID).
+			Update("qty", qty)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrItemNotFound
+		}
+		return touch(tx, cartID)
+	})
+}
+
+// RemoveItem deletes the variant's line, ErrItemNotFound if there is none
+func (r *Repo) RemoveItem(cartID, variantID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCart(tx, cartID); err != nil {
+			return err
+		}
+		res := tx.Where("cart_id = ? AND variant_id = ?", cartID, variantID).
+			Delete(&cartDomain.CartItem{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrItemNotFound
+		}
+		return touch(tx, cartID)
+	})
+}
+
+// touch bumps updated_at so active guest carts are not cleaned up
+func touch(db *gorm.DB, cartID int64) error {
+	return db.Model(&cartDomain.Cart{}).Where("id = ?", cartID).
+		Update("updated_at", time.Now()).Error
+}
+
+// lowerQty stores the quantities GetCart cut down to the remaining stock.
+// It takes the cart lock like any other change and only ever lowers a
+// line, so an update that landed since the read is not undone.
+func (r *Repo) lowerQty(cartID int64, items []cartDomain.CartItem) error {
+	if len(items) == 0 {
+		return nil
 	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCart(tx, cartID); err != nil {
+			return err
+		}
+		for _, it := range items {
+			err := tx.Model(&cartDomain.CartItem{}).Where("id = ? AND qty > ?", it.ID, it.Qty).
+				Update("qty", it.Qty).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
-	return r.db.Where("cart_id = ? AND variant_id = ?", cartID, variantID).
-		Delete(&cartDomain.CartItem{}).Error
+// lockCart serializes concurrent changes to one cart
+func lockCart(tx *gorm.DB, cartID int64) error {
+	var id int64
+	return tx.Raw("SELECT id FROM carts WHERE id = ? FOR UPDATE", cartID).Scan(&id).Error
 }
 
-func (r *Repo) GetCart(userID int64) (cartDomain.Cart, error) {
-	cartID, err := r.getOrCreateCartID(userID)
-	if err != nil {
+func (r *Repo) GetCart(cartID int64) (cartDomain.Cart, error) {
+	var out cartDomain.Cart
+	if err := r.db.First(&out, cartID).Error; err != nil {
 		return cartDomain.Cart{}, err
 	}
-
-	out := cartDomain.Cart{ID: cartID, UserID: userID}
+	out.Items = []cartDomain.CartItem{}
 
 	rows, err := r.db.Table("cart_items ci").
-		Select(`ci.id, ci.variant_id, ci.qty,
-		        p.id as product_id,
-		        p.name as product_name,
+		Select(`ci.id, ci.variant_id, ci.qty, ci.added_price,
+		        p.id as product_id, p.category_id,
+		        p.name as product_name, p.tax_class,
 		        c.name as category_name,
 		        pt.name as type_name,
 		        v.size, v.color,
-		        v.price, v.discount_percent,
-		        ROUND(v.price * (100 - v.discount_percent) / 100.0, 2) as final_price,
-		        v.stock_qty`).
+		        v.price, vp.discount_percent,
+		        vp.final_price, vp.sale_ends_at,
+		        v.stock_qty, v.weight_grams,
+		        (p.is_active AND c.is_active) as available`).
 		Joins("JOIN product_variants v ON v.id = ci.variant_id").
+		Joins("JOIN variant_prices vp ON vp.variant_id = v.id").
 		Joins("JOIN products p ON p.id = v.product_id").
 		Joins("JOIN categories c ON c.id = p.category_id").
 		Joins("JOIN product_types pt ON pt.id = p.type_id").
@@ -107,15 +292,37 @@ func (r *Repo) GetCart(userID int64) (cartDomain.Cart, error) {
 	for rows.Next() {
 		var it cartDomain.CartItem
 		if err := rows.Scan(
-			&it.ID, &it.VariantID, &it.Qty,
-			&it.ProductID, &it.Product, &it.Category, &it.TypeName,
+			&it.ID, &it.VariantID, &it.Qty, &it.AddedPrice,
+			&it.ProductID, &it.CategoryID, &it.Product, &it.TaxClass, &it.Category, &it.TypeName,
 			&it.Size, &it.Color,
-			&it.Price, &it.Discount, &it.FinalPrice, &it.StockQty,
+			&it.Price, &it.Discount, &it.FinalPrice, &it.SaleEndsAt, &it.StockQty, &it.WeightGrams,
+			&it.Available,
 		); err != nil {
 			return cartDomain.Cart{}, err
 		}
 		_ = math.Round(it.FinalPrice*100) / 100 // ensure precision
 		out.Items = append(out.Items, it)
 	}
+	if err := rows.Err(); err != nil {
+		return cartDomain.Cart{}, err
+	}
+	rows.Close()
+
+	var reduced []cartDomain.CartItem
+	for i := range out.Items {
+		if annotate(&out.Items[i]) {
+			reduced = append(reduced, out.Items[i])
+		}
+	}
+	if err := r.lowerQty(cartID, reduced); err != nil {
+		return cartDomain.Cart{}, err
+	}
+	out.Totals = computeTotals(out.Items)
+	if err := r.applyPromotions(&out); err != nil {
+		return cartDomain.Cart{}, err
+	}
+	if err := r.applyCoupon(&out); err != nil {
+		return cartDomain.Cart{}, err
+	}
 	return out, nil
 }
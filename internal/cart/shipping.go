@@ -0,0 +1,41 @@
+package cart
+
+import (
+	cartDomain "ecommerce/internal/domain/cart"
+	shippingDomain "ecommerce/internal/domain/shipping"
+	"ecommerce/internal/shipping"
+)
+
+// quoteShipping prices the shipping methods of the zone serving the
+// destination for the purchasable lines of a loaded cart. Rates and free
+// shipping thresholds look at the goods' value, so tax added on top of
+// the prices is left out.
+func (r *Repo) quoteShipping(crt cartDomain.Cart, country, region string) (cartDomain.ShippingQuote, error) {
+	value := crt.Totals.GrandTotal
+	if !crt.Totals.TaxIncluded {
+		value = round2(value - crt.Totals.Tax)
+	}
+	q := cartDomain.ShippingQuote{
+		CartValue:    value,
+		FreeShipping: crt.Totals.FreeShipping,
+		Methods:      []shippingDomain.Option{},
+		CartTotals:   crt.Totals,
+	}
+	for _, it := range crt.Items {
+		if purchasable(it) {
+			q.WeightGrams += it.WeightGrams * it.Qty
+		}
+	}
+
+	zone, methods, found, err := r.shipping.ForDestination(country, region)
+	if err != nil || !found {
+		return q, err
+	}
+	q.Zone = zone.Name
+	q.Methods = shipping.Quote(methods, shipping.Parcel{
+		WeightGrams:  q.WeightGrams,
+		Value:        q.CartValue,
+		FreeShipping: q.FreeShipping,
+	})
+	return q, nil
+}
@@ -0,0 +1,41 @@
+package cart
+
+import (
+	"context"
+
+	cartDomain "ecommerce/internal/domain/cart"
+	"ecommerce/internal/tax"
+)
+
+// applyTax adds tax for the destination to a loaded cart. Each line is
+// taxed on what the customer pays for it: its total after promotions and
+// its share of the coupon discount (split by line value).
+func applyTax(ctx context.Context, calc tax.Calculator, crt *cartDomain.Cart, dest tax.Destination, inclusive bool) error {
+	var lines []tax.Line
+	var sum float64
+	for _, it := range crt.Items {
+		if purchasable(it) {
+			amount := it.LineTotal - it.PromotionDiscount
+			lines = append(lines, tax.Line{Ref: it.VariantID, TaxClass: it.TaxClass, Amount: amount})
+			sum += amount
+		}
+	}
+	if coupon := crt.Totals.CouponDiscount; coupon > 0 && sum > 0 {
+		for i := range lines {
+			lines[i].Amount -= coupon * lines[i].Amount / sum
+		}
+	}
+
+	res, err := calc.Calculate(ctx, tax.Request{Destination: dest, Lines: lines, PricesIncludeTax: inclusive})
+	if err != nil {
+		return err
+	}
+	crt.Totals.Tax = res.Total
+	crt.Totals.TaxCountry = dest.Country
+	crt.Totals.TaxRegion = dest.Region
+	crt.Totals.TaxLines = res.Breakdown
+	if !inclusive {
+		crt.Totals.GrandTotal = round2(crt.Totals.GrandTotal + res.Total)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cart
+
+import (
+	"math"
+
+	cartDomain "ecommerce/internal/domain/cart"
+)
+
+// purchasable reports whether a line counts towards the totals
+func purchasable(it cartDomain.CartItem) bool {
+	return it.Available && it.StockQty > 0 && it.Qty > 0
+}
+
+// computeTotals fills each line total and returns the cart summary.
+// Call it after annotate so reduced quantities are used.
+func computeTotals(items []cartDomain.CartItem) cartDomain.Totals {
+	var t cartDomain.Totals
+	for i := range items {
+		it := &items[i]
+		it.LineTotal = round2(it.FinalPrice * float64(it.Qty))
+		if !purchasable(*it) {
+			continue
+		}
+		t.ItemCount += it.Qty
+		t.Subtotal += it.Price * float64(it.Qty)
+		t.ItemsTotal += it.LineTotal
+	}
+	t.Subtotal = round2(t.Subtotal)
+	t.ItemsTotal = round2(t.ItemsTotal)
+	t.Discount = round2(t.Subtotal - t.ItemsTotal)
+	t.GrandTotal = t.ItemsTotal
+	return t
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
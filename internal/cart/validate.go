@@ -0,0 +1,90 @@
+package cart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+
+	cartDomain "ecommerce/internal/domain/cart"
+)
+
+var (
+	ErrVariantNotFound = errors.New("variant not found")
+	ErrUnavailable     = errors.New("product unavailable")
+	ErrItemNotFound    = errors.New("item not in cart")
+)
+
+// StockError is returned when the requested line quantity exceeds stock
+type StockError struct {
+	Available int
+}
+
+func (e *StockError) Error() string {
+	return fmt.Sprintf("only %d in stock", e.Available)
+}
+
+// variantState is what cart validation needs to know about a variant
+type variantState struct {
+	StockQty   int
+	FinalPrice float64
+	Active     bool
+}
+
+func loadVariantState(db *gorm.DB, variantID int64) (variantState, error) {
+	var v variantState
+	res := db.Raw(`
+		SELECT v.stock_qty,
+		       vp.final_price,
+		       (p.is_active AND c.is_active) AS active
+		FROM product_variants v
+		JOIN variant_prices vp ON vp.variant_id = v.id
+		JOIN products p ON p.id = v.product_id
+		JOIN categories c ON c.id = p.category_id
+		WHERE v.id = ?`, variantID).Scan(&v)
+	if res.Error != nil {
+		return variantState{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return variantState{}, ErrVariantNotFound
+	}
+	return v, nil
+}
+
+// check validates a resulting line quantity against availability and stock
+func (v variantState) check(qty int) error {
+	if !v.Active {
+		return ErrUnavailable
+	}
+	if qty > v.StockQty {
+		return &StockError{Available: v.StockQty}
+	}
+	return nil
+}
+
+// annotate sets the line's warnings. It lowers Qty to the remaining stock
+// when needed and reports whether it did, so the caller can persist it.
+func annotate(it *cartDomain.CartItem) bool {
+	reduced := false
+	if !it.Available {
+		it.Warnings = append(it.Warnings, cartDomain.WarnUnavailable)
+	}
+	switch {
+	case it.StockQty <= 0:
+		it.Warnings = append(it.Warnings, cartDomain.WarnOutOfStock)
+	case it.Qty > it.StockQty:
+		it.Qty = it.StockQty
+		it.Warnings = append(it.Warnings, cartDomain.WarnQtyReduced)
+		reduced = true
+	}
+	if it.AddedPrice != nil && math.Abs(*it.AddedPrice-it.FinalPrice) >= 0.005 {
+		it.Warnings = append(it.Warnings, cartDomain.WarnPriceChanged)
+		it.PriceDelta = round2(it.FinalPrice - *it.AddedPrice)
+		it.PriceChange = cartDomain.PriceIncreased
+		if it.PriceDelta < 0 {
+			it.PriceChange = cartDomain.PriceDropped
+		}
+	}
+	return reduced
+}
@@ -1,14 +1,24 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"os"
 	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
 )
 
 type Config struct {
 	AppEnv   string
 	HTTPAddr string
 
+	// TrustedProxies lists the proxy IPs/CIDRs whose X-Forwarded-For header
+	// is believed when resolving the client IP; empty trusts none
+	TrustedProxies []string
+
 	DatabaseURL string
 
 	JWTIssuer           string
@@ -16,6 +26,8 @@ type Config struct {
 	JWTRefreshSecret    string
 	AccessTokenTTLMin   int
 	RefreshTokenTTLDays int
+	JWTSigningKeys      string
+	JWTHS256AcceptUntil string
 
 	SMTPHost string
 	SMTPPort int
@@ -27,6 +39,43 @@ type Config struct {
 	ResetPath  string
 
 	OTPTTLMin int
+
+	OIDCProviders []OIDCProvider
+
+	LoginMaxFailures    int
+	LoginIPMaxFailures  int
+	LoginLockoutBaseMin int
+	LoginLockoutMaxMin  int
+
+	ExportDir             string
+	ExportLinkTTLMin      int
+	ExportBuildTimeoutMin int
+	DownloadSigningSecret string
+
+	PasswordMinLength     int
+	PasswordRequireUpper  bool
+	PasswordRequireLower  bool
+	PasswordRequireDigit  bool
+	PasswordRequireSymbol bool
+	PasswordBreachedList  string
+
+	PasswordHashAlgo  string
+	BcryptCost        int
+	Argon2Time        int
+	Argon2MemoryKB    int
+	Argon2Parallelism int
+
+	PricesIncludeTax bool
+}
+
+// OIDCProvider is configured via OIDC_PROVIDERS=google,corp and
+// OIDC_<NAME>_ISSUER / _CLIENT_ID / _CLIENT_SECRET / _SCOPES
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
 }
 
 func Load() Config {
@@ -34,6 +83,8 @@ func Load() Config {
 		AppEnv:    get("APP_ENV", "dev"),
 		HTTPAddr: get("HTTP_ADDR", ":8080"),
 
+		TrustedProxies: getList("TRUSTED_PROXIES"),
+
 		DatabaseURL: get("DATABASE_URL", ""),
 
 		JWTIssuer:           get("JWT_ISSUER", "ecommerce"),
@@ -41,6 +92,8 @@ func Load() Config {
 		JWTRefreshSecret:    get("JWT_REFRESH_SECRET", ""),
 		AccessTokenTTLMin:   getInt("ACCESS_TOKEN_TTL_MIN", 15),
 		RefreshTokenTTLDays: getInt("REFRESH_TOKEN_TTL_DAYS", 30),
+		JWTSigningKeys:      get("JWT_SIGNING_KEYS", ""),
+		JWTHS256AcceptUntil: get("JWT_HS256_ACCEPT_UNTIL", ""),
 
 		SMTPHost: get("SMTP_HOST", ""),
 		SMTPPort: getInt("SMTP_PORT", 587),
@@ -52,9 +105,57 @@ func Load() Config {
 		ResetPath:  get("RESET_PATH", "/reset-password"),
 
 		OTPTTLMin: getInt("OTP_TTL_MIN", 10),
+
+		OIDCProviders: loadOIDCProviders(),
+
+		LoginMaxFailures:    getInt("LOGIN_MAX_FAILURES", 5),
+		LoginIPMaxFailures:  getInt("LOGIN_IP_MAX_FAILURES", 20),
+		LoginLockoutBaseMin: getInt("LOGIN_LOCKOUT_BASE_MIN", 1),
+		LoginLockoutMaxMin:  getInt("LOGIN_LOCKOUT_MAX_MIN", 24*60),
+
+		ExportDir:             get("EXPORT_DIR", "./data/exports"),
+		ExportLinkTTLMin:      getInt("EXPORT_LINK_TTL_MIN", 60),
+		ExportBuildTimeoutMin: getInt("EXPORT_BUILD_TIMEOUT_MIN", 30),
+		// signs emailed links (export downloads, restock confirmations);
+		// without a dedicated key, derive one so a link signature never
+		// doubles as a refresh token signature
+		DownloadSigningSecret: get("DOWNLOAD_SIGNING_SECRET", deriveKey(get("JWT_REFRESH_SECRET", ""), "download-links")),
+
+		PasswordMinLength:     getInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireUpper:  getBool("PASSWORD_REQUIRE_UPPER", true),
+		PasswordRequireLower:  getBool("PASSWORD_REQUIRE_LOWER", true),
+		PasswordRequireDigit:  getBool("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireSymbol: getBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordBreachedList:  get("PASSWORD_BREACHED_LIST", ""), // empty: the list built into the binary
+
+		// defaults follow the OWASP argon2id recommendation (19 MiB, t=2, p=1)
+		PasswordHashAlgo:  get("PASSWORD_HASH_ALGO", "argon2id"),
+		BcryptCost:        getInt("BCRYPT_COST", 10),
+		Argon2Time:        getInt("ARGON2_TIME", 2),
+		Argon2MemoryKB:    getInt("ARGON2_MEMORY_KB", 19*1024),
+		Argon2Parallelism: getInt("ARGON2_PARALLELISM", 1),
+
+		// true: catalog prices are gross and tax is shown as included;
+		// false: tax is added on top of the cart total
+		PricesIncludeTax: getBool("PRICES_INCLUDE_TAX", false),
 	}
 }
 
+func loadOIDCProviders() []OIDCProvider {
+	var out []OIDCProvider
+	for _, name := range getList("OIDC_PROVIDERS") {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		out = append(out, OIDCProvider{
+			Name:         strings.ToLower(name),
+			Issuer:       get(prefix+"ISSUER", ""),
+			ClientID:     get(prefix+"CLIENT_ID", ""),
+			ClientSecret: get(prefix+"CLIENT_SECRET", ""),
+			Scopes:       getList(prefix + "SCOPES"),
+		})
+	}
+	return out
+}
+
 func get(k, def string) string {
 	if v := os.Getenv(k); v != "" {
 		return v
@@ -71,3 +172,35 @@ func getInt(k string, def int) int {
 	}
 	return def
 }
+
+func getBool(k string, def bool) bool {
+	if v := os.Getenv(k); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+// deriveKey derives a separate key for one purpose from secret (HKDF-SHA256)
+func deriveKey(secret, purpose string) string {
+	if secret == "" {
+		return ""
+	}
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte(purpose)), key); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(key)
+}
+
+func getList(k string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(k), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
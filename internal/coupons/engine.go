@@ -0,0 +1,115 @@
+package coupons
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"ecommerce/internal/domain/coupon"
+)
+
+// Reasons a coupon cannot be applied. Messages are shown to the customer.
+var (
+	ErrInactive    = errors.New("coupon is not active")
+	ErrNotStarted  = errors.New("coupon is not valid yet")
+	ErrExpired     = errors.New("coupon has expired")
+	ErrNotEligible = errors.New("coupon does not apply to any item in your cart")
+)
+
+// IsRejection reports whether err is one of the customer-facing reasons above
+func IsRejection(err error) bool {
+	var minErr *MinCartError
+	if errors.As(err, &minErr) {
+		return true
+	}
+	for _, e := range []error{ErrInactive, ErrNotStarted, ErrExpired, ErrNotEligible} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// MinCartError is returned when the cart is below the coupon's minimum
+type MinCartError struct {
+	Min float64
+}
+
+func (e *MinCartError) Error() string {
+	return fmt.Sprintf("cart total must be at least %.2f", e.Min)
+}
+
+// Line is the part of a cart line the engine looks at
+type Line struct {
+	ProductID  int64
+	CategoryID int64
+	Amount     float64 // line total after variant discounts
+}
+
+type Result struct {
+	Discount     float64
+	FreeShipping bool
+}
+
+// Evaluate checks c against the cart and returns the discount it gives.
+// cartValue is the amount the minimum is compared against.
+func Evaluate(c coupon.Coupon, lines []Line, cartValue float64, now time.Time) (Result, error) {
+	switch {
+	case !c.IsActive:
+		return Result{}, ErrInactive
+	case c.StartsAt != nil && now.Before(*c.StartsAt):
+		return Result{}, ErrNotStarted
+	case c.EndsAt != nil && !now.Before(*c.EndsAt):
+		return Result{}, ErrExpired
+	}
+	if cartValue < c.MinCartValue {
+		return Result{}, &MinCartError{Min: c.MinCartValue}
+	}
+
+	eligible := 0.0
+	matched := false
+	for _, l := range lines {
+		if appliesTo(c, l) {
+			eligible += l.Amount
+			matched = true
+		}
+	}
+	if !matched {
+		return Result{}, ErrNotEligible
+	}
+
+	switch c.Kind {
+	case coupon.KindPercent:
+		return Result{Discount: round2(eligible * c.Value / 100)}, nil
+	case coupon.KindFixed:
+		return Result{Discount: round2(math.Min(c.Value, eligible))}, nil
+	case coupon.KindFreeShipping:
+		return Result{FreeShipping: true}, nil
+	default:
+		return Result{}, ErrInactive
+	}
+}
+
+// appliesTo reports whether a line matches the coupon's restrictions.
+// Without restrictions every line matches.
+func appliesTo(c coupon.Coupon, l Line) bool {
+	if len(c.CategoryIDs) == 0 && len(c.ProductIDs) == 0 {
+		return true
+	}
+	for _, id := range c.ProductIDs {
+		if id == l.ProductID {
+			return true
+		}
+	}
+	for _, id := range c.CategoryIDs {
+		if id == l.CategoryID {
+			return true
+		}
+	}
+	return false
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,74 @@
+package coupons
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ecommerce/internal/domain/coupon"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+	lines := []Line{
+		{ProductID: 1, CategoryID: 10, Amount: 30},
+		{ProductID: 2, CategoryID: 20, Amount: 20},
+	}
+	percent := func(v float64) coupon.Coupon {
+		return coupon.Coupon{Kind: coupon.KindPercent, Value: v, IsActive: true}
+	}
+
+	tests := []struct {
+		name      string
+		coupon    func() coupon.Coupon
+		lines     []Line
+		cartValue float64
+		want      Result
+		err       error
+	}{
+		{"percent of the whole cart", func() coupon.Coupon { return percent(10) }, lines, 50, Result{Discount: 5}, nil},
+		{"inactive", func() coupon.Coupon { c := percent(10); c.IsActive = false; return c }, lines, 50, Result{}, ErrInactive},
+		{"not started yet", func() coupon.Coupon { c := percent(10); c.StartsAt = &after; return c }, lines, 50, Result{}, ErrNotStarted},
+		{"inside the window", func() coupon.Coupon { c := percent(10); c.StartsAt, c.EndsAt = &before, &after; return c }, lines, 50, Result{Discount: 5}, nil},
+		{"expired", func() coupon.Coupon { c := percent(10); c.EndsAt = &before; return c }, lines, 50, Result{}, ErrExpired},
+		{"ends_at is exclusive", func() coupon.Coupon { c := percent(10); c.EndsAt = &now; return c }, lines, 50, Result{}, ErrExpired},
+		{"starts_at is inclusive", func() coupon.Coupon { c := percent(10); c.StartsAt = &now; return c }, lines, 50, Result{Discount: 5}, nil},
+		{"below the minimum", func() coupon.Coupon { c := percent(10); c.MinCartValue = 60; return c }, lines, 50, Result{}, &MinCartError{Min: 60}},
+		{"at the minimum", func() coupon.Coupon { c := percent(10); c.MinCartValue = 50; return c }, lines, 50, Result{Discount: 5}, nil},
+		{"category restriction discounts matching lines only", func() coupon.Coupon { c := percent(10); c.CategoryIDs = []int64{20}; return c }, lines, 50, Result{Discount: 2}, nil},
+		{"product or category restriction", func() coupon.Coupon {
+			c := percent(10)
+			c.ProductIDs, c.CategoryIDs = []int64{1}, []int64{20}
+			return c
+		}, lines, 50, Result{Discount: 5}, nil},
+		{"restriction matching nothing", func() coupon.Coupon { c := percent(10); c.ProductIDs = []int64{99}; return c }, lines, 50, Result{}, ErrNotEligible},
+		{"empty cart", func() coupon.Coupon { return percent(10) }, nil, 0, Result{}, ErrNotEligible},
+		{"fixed amount", func() coupon.Coupon { return coupon.Coupon{Kind: coupon.KindFixed, Value: 15, IsActive: true} }, lines, 50, Result{Discount: 15}, nil},
+		{"fixed amount is capped at the eligible total", func() coupon.Coupon {
+			return coupon.Coupon{Kind: coupon.KindFixed, Value: 25, IsActive: true, CategoryIDs: []int64{20}}
+		}, lines, 50, Result{Discount: 20}, nil},
+		{"free shipping", func() coupon.Coupon { return coupon.Coupon{Kind: coupon.KindFreeShipping, IsActive: true} }, lines, 50, Result{FreeShipping: true}, nil},
+		{"percent is rounded to cents", func() coupon.Coupon { return percent(15) }, []Line{{ProductID: 1, Amount: 0.33}}, 0.33, Result{Discount: 0.05}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Evaluate(tt.coupon(), tt.lines, tt.cartValue, now)
+			var minErr, wantMin *MinCartError
+			switch {
+			case errors.As(tt.err, &wantMin):
+				if !errors.As(err, &minErr) || minErr.Min != wantMin.Min {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+			case !errors.Is(err, tt.err):
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil && !IsRejection(err) {
+				t.Errorf("%v is not reported as a rejection", err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package coupons
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/audit"
+	"ecommerce/internal/domain/coupon"
+)
+
+type Handler struct {
+	repo *Repo
+}
+
+func NewHandler(repo *Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+type CouponReq struct {
+	Code         string     `json:"code" binding:"required"`
+	Description  string     `json:"description"`
+	Kind         string     `json:"kind" binding:"required"`
+	Value        float64    `json:"value"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	MinCartValue float64    `json:"min_cart_value"`
+	IsActive     *bool      `json:"is_active"`
+	CategoryIDs  []int64    `json:"category_ids"`
+	ProductIDs   []int64    `json:"product_ids"`
+}
+
+// toCoupon validates the request and builds the coupon
+func (req CouponReq) toCoupon() (coupon.Coupon, string) {
+	c := coupon.Coupon{
+		Code:         strings.TrimSpace(req.Code),
+		Description:  req.Description,
+		Kind:         req.Kind,
+		Value:        req.Value,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		MinCartValue: req.MinCartValue,
+		IsActive:     req.IsActive == nil || *req.IsActive,
+		CategoryIDs:  req.CategoryIDs,
+		ProductIDs:   req.ProductIDs,
+	}
+	switch {
+	case c.Code == "" || strings.ContainsAny(c.Code, " \t"):
+		return c, "code must be a single word"
+	case c.Kind != coupon.KindPercent && c.Kind != coupon.KindFixed && c.Kind != coupon.KindFreeShipping:
+		return c, "kind must be percent, fixed or free_shipping"
+	case c.Value < 0 || c.MinCartValue < 0:
+		return c, "amounts must not be negative"
+	case c.Kind == coupon.KindPercent && (c.Value <= 0 || c.Value > 100):
+		return c, "percent value must be between 0 and 100"
+	case c.Kind == coupon.KindFixed && c.Value <= 0:
+		return c, "fixed value must be positive"
+	case c.StartsAt != nil && c.EndsAt != nil && !c.EndsAt.After(*c.StartsAt):
+		return c, "ends_at must be after starts_at"
+	}
+	if c.Kind == coupon.KindFreeShipping {
+		c.Value = 0
+	}
+	return c, ""
+}
+
+func (h *Handler) AdminList(c *gin.Context) {
+	page, pageSize := audit.Pagination(c)
+	items, total, err := h.repo.List(pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list coupons"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total, "page": page, "page_size": pageSize})
+}
+
+func (h *Handler) AdminGet(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	cp, err := h.repo.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "coupon not found"})
+		return
+	}
+	c.JSON(http.StatusOK, cp)
+}
+
+func (h *Handler) AdminCreate(c *gin.Context) {
+	var req CouponReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	cp, msg := req.toCoupon()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.repo.Create(&cp); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to create coupon (code may be duplicate)"})
+		return
+	}
+	cp, _ = h.repo.ByID(cp.ID)
+	c.JSON(http.StatusCreated, cp)
+}
+
+// AdminUpdate replaces the coupon definition (PUT semantics)
+func (h *Handler) AdminUpdate(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req CouponReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	cp, msg := req.toCoupon()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	cp.ID = id
+	if err := h.repo.Update(&cp); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "coupon not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to update coupon"})
+		return
+	}
+	cp, _ = h.repo.ByID(id)
+	c.JSON(http.StatusOK, cp)
+}
+
+func (h *Handler) AdminDelete(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.repo.Delete(id); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "coupon not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete coupon"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
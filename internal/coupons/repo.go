@@ -0,0 +1,130 @@
+package coupons
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"ecommerce/internal/domain/coupon"
+)
+
+var ErrNotFound = errors.New("coupon not found")
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) List(limit, offset int) ([]coupon.Coupon, int64, error) {
+	var total int64
+	if err := r.db.Model(&coupon.Coupon{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []coupon.Coupon
+	if err := r.db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&out).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range out {
+		if err := r.load(&out[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+	return out, total, nil
+}
+
+func (r *Repo) ByID(id int64) (coupon.Coupon, error) {
+	return r.find(r.db.Where("id = ?", id))
+}
+
+// ByCode looks a coupon up case-insensitively (code is citext)
+func (r *Repo) ByCode(code string) (coupon.Coupon, error) {
+	return r.find(r.db.Where("code = ?", code))
+}
+
+func (r *Repo) find(q *gorm.DB) (coupon.Coupon, error) {
+	var c coupon.Coupon
+	err := q.First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c, ErrNotFound
+	}
+	if err != nil {
+		return c, err
+	}
+	return c, r.load(&c)
+}
+
+// load fills restrictions and the use count
+func (r *Repo) load(c *coupon.Coupon) error {
+	c.CategoryIDs = []int64{}
+	c.ProductIDs = []int64{}
+	if err := r.db.Model(&coupon.CouponCategory{}).Where("coupon_id = ?", c.ID).
+		Order("category_id").Pluck("category_id", &c.CategoryIDs).Error; err != nil {
+		return err
+	}
+	if err := r.db.Model(&coupon.CouponProduct{}).Where("coupon_id = ?", c.ID).
+		Order("product_id").Pluck("product_id", &c.ProductIDs).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Create inserts the coupon with its restrictions
+func (r *Repo) Create(c *coupon.Coupon) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(c).Error; err != nil {
+			return err
+		}
+		return setRestrictions(tx, c.ID, c.CategoryIDs, c.ProductIDs)
+	})
+}
+
+// Update saves all coupon fields and replaces its restrictions
+func (r *Repo) Update(c *coupon.Coupon) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&coupon.Coupon{}).Where("id = ?", c.ID).Select(
+			"code", "description", "kind", "value", "starts_at", "ends_at",
+			"min_cart_value", "is_active",
+		).Updates(c)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return setRestrictions(tx, c.ID, c.CategoryIDs, c.ProductIDs)
+	})
+}
+
+func (r *Repo) Delete(id int64) error {
+	res := r.db.Delete(&coupon.Coupon{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func setRestrictions(tx *gorm.DB, couponID int64, categoryIDs, productIDs []int64) error {
+	if err := tx.Where("coupon_id = ?", couponID).Delete(&coupon.CouponCategory{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("coupon_id = ?", couponID).Delete(&coupon.CouponProduct{}).Error; err != nil {
+		return err
+	}
+	for _, id := range categoryIDs {
+		if err := tx.Create(&coupon.CouponCategory{CouponID: couponID, CategoryID: id}).Error; err != nil {
+			return err
+		}
+	}
+	for _, id := range productIDs {
+		if err := tx.Create(&coupon.CouponProduct{CouponID: couponID, ProductID: id}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
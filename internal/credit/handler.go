@@ -0,0 +1,267 @@
+package credit
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/audit"
+	"ecommerce/internal/auth"
+)
+
+type Handler struct {
+	repo  *Repo
+	audit *audit.Repo
+}
+
+func NewHandler(repo *Repo, auditRepo *audit.Repo) *Handler {
+	return &Handler{repo: repo, audit: auditRepo}
+}
+
+type codeReq struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type issueReq struct {
+	Amount         float64    `json:"amount" binding:"required"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	RecipientEmail *string    `json:"recipient_email"`
+}
+
+type adjustReq struct {
+	Amount    float64 `json:"amount" binding:"required"` // signed delta
+	Reference string  `json:"reference"`
+}
+
+type walletReq struct {
+	Amount    float64 `json:"amount" binding:"required"`
+	Reason    string  `json:"reason"` // adjust (signed amount) or refund (positive)
+	Reference string  `json:"reference"`
+}
+
+type activeReq struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
+
+// StoreCredit returns the current user's balance and ledger
+func (h *Handler) StoreCredit(c *gin.Context) {
+	h.walletResponse(c, c.GetInt64(auth.CtxUserIDKey))
+}
+
+// RedeemGiftCard moves a gift card balance into the user's store credit
+func (h *Handler) RedeemGiftCard(c *gin.Context) {
+	userID := c.GetInt64(auth.CtxUserIDKey)
+	var req codeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	amount, err := h.repo.TransferGiftCardToWallet(req.Code, userID)
+	if err != nil {
+		respondError(c, err, "failed to redeem gift card")
+		return
+	}
+	balance, _ := h.repo.WalletBalance(userID)
+	c.JSON(http.StatusOK, gin.H{"credited": amount, "balance": balance})
+}
+
+// GiftCardBalance is a public lookup by code; it only reveals the balance
+func (h *Handler) GiftCardBalance(c *gin.Context) {
+	g, err := h.repo.GiftCardByCode(c.Query("code"))
+	if err != nil {
+		respondError(c, err, "failed to load gift card")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"last4":      g.Last4,
+		"balance":    g.Balance,
+		"expires_at": g.ExpiresAt,
+		"usable":     g.Usable(time.Now()),
+	})
+}
+
+func (h *Handler) AdminListGiftCards(c *gin.Context) {
+	page, pageSize := audit.Pagination(c)
+	items, total, err := h.repo.ListGiftCards(pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list gift cards"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total, "page": page, "page_size": pageSize})
+}
+
+// AdminGetGiftCard returns the card with its ledger (?page=&page_size=)
+func (h *Handler) AdminGetGiftCard(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	g, err := h.repo.GiftCardByID(id)
+	if err != nil {
+		respondError(c, err, "failed to load gift card")
+		return
+	}
+	page, pageSize := audit.Pagination(c)
+	entries, total, err := h.repo.Ledger(&g.ID, nil, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load ledger"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"gift_card": g,
+		"ledger":    gin.H{"items": entries, "total": total, "page": page, "page_size": pageSize},
+	})
+}
+
+// AdminIssueGiftCard creates a card; the code is only returned here
+func (h *Handler) AdminIssueGiftCard(c *gin.Context) {
+	var req issueReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+	if req.RecipientEmail != nil {
+		email := strings.TrimSpace(strings.ToLower(*req.RecipientEmail))
+		req.RecipientEmail = &email
+		if email == "" {
+			req.RecipientEmail = nil
+		}
+	}
+
+	actorID := c.GetInt64(auth.CtxUserIDKey)
+	g, code, err := h.repo.IssueGiftCard(req.Amount, req.ExpiresAt, req.RecipientEmail, &actorID)
+	if err != nil {
+		respondError(c, err, "failed to issue gift card")
+		return
+	}
+	h.record(c, "gift_card.issue", g.ID, gin.H{"amount": g.InitialBalance})
+	c.JSON(http.StatusCreated, gin.H{"gift_card": g, "code": code})
+}
+
+// AdminAdjustGiftCard changes a card balance by a signed amount
+func (h *Handler) AdminAdjustGiftCard(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var req adjustReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	actorID := c.GetInt64(auth.CtxUserIDKey)
+	g, err := h.repo.AdjustGiftCard(id, req.Amount, strings.TrimSpace(req.Reference), &actorID)
+	if err != nil {
+		respondError(c, err, "failed to adjust gift card")
+		return
+	}
+	h.record(c, "gift_card.adjust", g.ID, gin.H{"amount": req.Amount, "balance": g.Balance})
+	c.JSON(http.StatusOK, g)
+}
+
+// AdminSetGiftCardActive disables or re-enables a card
+func (h *Handler) AdminSetGiftCardActive(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var req activeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if err := h.repo.SetGiftCardActive(id, *req.IsActive); err != nil {
+		respondError(c, err, "failed to update gift card")
+		return
+	}
+	h.record(c, "gift_card.set_active", id, gin.H{"value": *req.IsActive})
+	g, _ := h.repo.GiftCardByID(id)
+	c.JSON(http.StatusOK, g)
+}
+
+func (h *Handler) AdminUserStoreCredit(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	h.walletResponse(c, id)
+}
+
+// AdminChangeUserStoreCredit adjusts a wallet or credits a refund to it.
+// Refunds to the original payment method are not handled here since there
+// is no payment integration; choosing store credit is the only option.
+func (h *Handler) AdminChangeUserStoreCredit(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var req walletReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	actorID := c.GetInt64(auth.CtxUserIDKey)
+	ref := strings.TrimSpace(req.Reference)
+
+	var (
+		balance float64
+		err     error
+	)
+	switch req.Reason {
+	case "", "adjust":
+		balance, err = h.repo.AdjustWallet(id, req.Amount, ref, &actorID)
+	case "refund":
+		balance, err = h.repo.RefundToStoreCredit(id, req.Amount, ref, &actorID)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason must be adjust or refund"})
+		return
+	}
+	if err != nil {
+		respondError(c, err, "failed to update store credit")
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = "adjust"
+	}
+	h.recordUser(c, "store_credit."+req.Reason, id, gin.H{"amount": req.Amount, "balance": balance, "reference": ref})
+	c.JSON(http.StatusOK, gin.H{"balance": balance})
+}
+
+func (h *Handler) walletResponse(c *gin.Context, userID int64) {
+	balance, err := h.repo.WalletBalance(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load store credit"})
+		return
+	}
+	page, pageSize := audit.Pagination(c)
+	entries, total, err := h.repo.Ledger(nil, &userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load ledger"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"balance": balance,
+		"ledger":  gin.H{"items": entries, "total": total, "page": page, "page_size": pageSize},
+	})
+}
+
+// record writes an audit entry for a gift card; failures are not fatal
+func (h *Handler) record(c *gin.Context, action string, giftCardID int64, details any) {
+	h.recordTarget(c, action, "gift_card", giftCardID, details)
+}
+
+func (h *Handler) recordUser(c *gin.Context, action string, userID int64, details any) {
+	h.recordTarget(c, action, "user", userID, details)
+}
+
+func (h *Handler) recordTarget(c *gin.Context, action, targetType string, targetID int64, details any) {
+	if h.audit == nil {
+		return
+	}
+	actorID := c.GetInt64(auth.CtxUserIDKey)
+	_ = h.audit.Record(&actorID, action, targetType, strconv.FormatInt(targetID, 10), c.ClientIP(), details)
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrGiftCardNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrGiftCardUnusable), errors.Is(err, ErrInsufficientBalance), errors.Is(err, ErrInvalidAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,290 @@
+package credit
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"ecommerce/internal/domain/credit"
+	"ecommerce/internal/util"
+)
+
+var (
+	ErrGiftCardNotFound    = errors.New("gift card not found")
+	ErrGiftCardUnusable    = errors.New("gift card is expired or disabled")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrInvalidAmount       = errors.New("invalid amount")
+)
+
+const giftCodeLength = 16
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// NormalizeCode uppercases a gift card code and strips separators
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// IssueGiftCard creates a card and returns it with its code. The code is
+// only stored hashed, so this is the one chance to show or email it.
+func (r *Repo) IssueGiftCard(amount float64, expiresAt *time.Time, recipient *string, actorID *int64) (credit.GiftCard, string, error) {
+	amount = round2(amount)
+	if amount <= 0 {
+		return credit.GiftCard{}, "", ErrInvalidAmount
+	}
+	raw, err := util.RandomCode(giftCodeLength)
+	if err != nil {
+		return credit.GiftCard{}, "", err
+	}
+	code := NormalizeCode(raw)
+
+	g := credit.GiftCard{
+		CodeHash:       util.HashToken(code),
+		Last4:          code[len(code)-4:],
+		InitialBalance: amount,
+		Balance:        amount,
+		RecipientEmail: recipient,
+		ExpiresAt:      expiresAt,
+		IsActive:       true,
+		IssuedBy:       actorID,
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&g).Error; err != nil {
+			return err
+		}
+		return tx.Create(&credit.LedgerEntry{
+			GiftCardID: &g.ID, Amount: amount, BalanceAfter: amount,
+			Reason: credit.ReasonIssue, ActorID: actorID,
+		}).Error
+	})
+	if err != nil {
+		return credit.GiftCard{}, "", err
+	}
+	return g, formatCode(code), nil
+}
+
+func (r *Repo) ListGiftCards(limit, offset int) ([]credit.GiftCard, int64, error) {
+	var total int64
+	if err := r.db.Model(&credit.GiftCard{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []credit.GiftCard
+	err := r.db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&out).Error
+	return out, total, err
+}
+
+func (r *Repo) GiftCardByID(id int64) (credit.GiftCard, error) {
+	var g credit.GiftCard
+	err := r.db.First(&g, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return g, ErrGiftCardNotFound
+	}
+	return g, err
+}
+
+func (r *Repo) GiftCardByCode(code string) (credit.GiftCard, error) {
+	var g credit.GiftCard
+	err := r.db.Where("code_hash = ?", util.HashToken(NormalizeCode(code))).First(&g).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return g, ErrGiftCardNotFound
+	}
+	return g, err
+}
+
+// SetGiftCardActive enables or disables a card without touching its balance
+func (r *Repo) SetGiftCardActive(id int64, active bool) error {
+	res := r.db.Model(&credit.GiftCard{}).Where("id = ?", id).Update("is_active", active)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrGiftCardNotFound
+	}
+	return nil
+}
+
+// AdjustGiftCard changes a card balance by delta (admin correction)
+func (r *Repo) AdjustGiftCard(id int64, delta float64, reference string, actorID *int64) (credit.GiftCard, error) {
+	var g credit.GiftCard
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		g, err = lockGiftCard(tx.Where("id = ?", id))
+		if err != nil {
+			return err
+		}
+		_, err = changeGiftCard(tx, &g, delta, credit.ReasonAdjust, reference, actorID)
+		return err
+	})
+	return g, err
+}
+
+// TransferGiftCardToWallet moves the whole card balance into the user's
+// store credit, so it can be spent without typing the code again.
+func (r *Repo) TransferGiftCardToWallet(code string, userID int64) (float64, error) {
+	var moved float64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		g, err := lockGiftCard(tx.Where("code_hash = ?", util.HashToken(NormalizeCode(code))))
+		if err != nil {
+			return err
+		}
+		if !g.Usable(time.Now()) {
+			return ErrGiftCardUnusable
+		}
+		if g.Balance <= 0 {
+			return ErrInsufficientBalance
+		}
+		ref := "gift card ****" + g.Last4
+		if moved, err = changeGiftCard(tx, &g, -g.Balance, credit.ReasonTransfer, ref, &userID); err != nil {
+			return err
+		}
+		_, _, err = changeWallet(tx, userID, moved, credit.ReasonTransfer, ref, &userID)
+		return err
+	})
+	return moved, err
+}
+
+// WalletBalance returns the user's store credit (0 without a wallet)
+func (r *Repo) WalletBalance(userID int64) (float64, error) {
+	var w credit.Wallet
+	err := r.db.Where("user_id = ?", userID).Limit(1).Find(&w).Error
+	return w.Balance, err
+}
+
+// AdjustWallet changes store credit by delta (admin correction)
+func (r *Repo) AdjustWallet(userID int64, delta float64, reference string, actorID *int64) (float64, error) {
+	var balance float64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		_, balance, err = changeWallet(tx, userID, delta, credit.ReasonAdjust, reference, actorID)
+		return err
+	})
+	return balance, err
+}
+
+// RefundToStoreCredit credits a refund to the wallet instead of the
+// original payment method.
+func (r *Repo) RefundToStoreCredit(userID int64, amount float64, reference string, actorID *int64) (float64, error) {
+	if amount <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	var balance float64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		_, balance, err = changeWallet(tx, userID, amount, credit.ReasonRefund, reference, actorID)
+		return err
+	})
+	return balance, err
+}
+
+// Ledger lists entries for a gift card or a user, newest first
+func (r *Repo) Ledger(giftCardID, userID *int64, limit, offset int) ([]credit.LedgerEntry, int64, error) {
+	q := r.db.Model(&credit.LedgerEntry{})
+	if giftCardID != nil {
+		q = q.Where("gift_card_id = ?", *giftCardID)
+	}
+	if userID != nil {
+		q = q.Where("user_id = ?", *userID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []credit.LedgerEntry
+	err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&out).Error
+	return out, total, err
+}
+
+func lockGiftCard(q *gorm.DB) (credit.GiftCard, error) {
+	var g credit.GiftCard
+	err := q.Clauses(clause.Locking{Strength: "UPDATE"}).First(&g).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return g, ErrGiftCardNotFound
+	}
+	return g, err
+}
+
+// changeGiftCard applies delta to a locked card and writes the ledger entry.
+// It returns the absolute amount moved.
+func changeGiftCard(tx *gorm.DB, g *credit.GiftCard, delta float64, reason, reference string, actorID *int64) (float64, error) {
+	delta, err := checkDelta(g.Balance, delta)
+	if err != nil {
+		return 0, err
+	}
+	g.Balance = round2(g.Balance + delta)
+	if err := tx.Model(g).Update("balance", g.Balance).Error; err != nil {
+		return 0, err
+	}
+	err = tx.Create(&credit.LedgerEntry{
+		GiftCardID: &g.ID, Amount: delta, BalanceAfter: g.Balance,
+		Reason: reason, Reference: reference, ActorID: actorID,
+	}).Error
+	return math.Abs(delta), err
+}
+
+// changeWallet is changeGiftCard for store credit; the wallet row is
+// created on first use. Returns the amount moved and the new balance.
+func changeWallet(tx *gorm.DB, userID int64, delta float64, reason, reference string, actorID *int64) (float64, float64, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&credit.Wallet{UserID: userID}).Error; err != nil {
+		return 0, 0, err
+	}
+	var w credit.Wallet
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).First(&w).Error; err != nil {
+		return 0, 0, err
+	}
+
+	delta, err := checkDelta(w.Balance, delta)
+	if err != nil {
+		return 0, 0, err
+	}
+	w.Balance = round2(w.Balance + delta)
+	if err := tx.Model(&credit.Wallet{}).Where("user_id = ?", userID).
+		Update("balance", w.Balance).Error; err != nil {
+		return 0, 0, err
+	}
+	err = tx.Create(&credit.LedgerEntry{
+		UserID: &userID, Amount: delta, BalanceAfter: w.Balance,
+		Reason: reason, Reference: reference, ActorID: actorID,
+	}).Error
+	return math.Abs(delta), w.Balance, err
+}
+
+// checkDelta rounds delta and refuses to take the balance below zero
+func checkDelta(balance, delta float64) (float64, error) {
+	delta = round2(delta)
+	if delta == 0 {
+		return 0, ErrInvalidAmount
+	}
+	if balance+delta < 0 {
+		return 0, ErrInsufficientBalance
+	}
+	return delta, nil
+}
+
+// formatCode groups a code in fours for display: ABCD-EFGH-JKLM-NPQR
+func formatCode(code string) string {
+	var b strings.Builder
+	for i, ch := range code {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,24 @@
+package address
+
+import "time"
+
+type Address struct {
+	ID                int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID            int64     `json:"-" gorm:"not null;index"`
+	Label             string    `json:"label" gorm:"type:text;not null;default:''"`
+	FullName          string    `json:"full_name" gorm:"type:text;not null"`
+	Company           string    `json:"company" gorm:"type:text;not null;default:''"`
+	Line1             string    `json:"line1" gorm:"column:line1;type:text;not null"`
+	Line2             string    `json:"line2" gorm:"column:line2;type:text;not null;default:''"`
+	City              string    `json:"city" gorm:"type:text;not null"`
+	Region            string    `json:"region" gorm:"type:text;not null;default:''"`
+	PostalCode        string    `json:"postal_code" gorm:"type:text;not null;default:''"`
+	Country           string    `json:"country" gorm:"type:char(2);not null"`
+	Phone             string    `json:"phone" gorm:"type:text;not null;default:''"`
+	IsDefaultShipping bool      `json:"is_default_shipping" gorm:"not null;default:false"`
+	IsDefaultBilling  bool      `json:"is_default_billing" gorm:"not null;default:false"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Address) TableName() string { return "addresses" }
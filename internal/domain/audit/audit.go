@@ -0,0 +1,19 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Entry struct {
+	ID         int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	ActorID    *int64          `json:"actor_id,omitempty" gorm:"index"`
+	Action     string          `json:"action" gorm:"type:text;not null"`
+	TargetType string          `json:"target_type" gorm:"type:text;not null"`
+	TargetID   string          `json:"target_id" gorm:"type:text;not null"`
+	Details    json.RawMessage `json:"details,omitempty" gorm:"type:jsonb"`
+	IP         string          `json:"ip,omitempty" gorm:"column:ip;type:text"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (Entry) TableName() string { return "audit_log" }
@@ -1,36 +1,134 @@
 package cart
 
-import "time"
+import (
+	"time"
+
+	"ecommerce/internal/domain/shipping"
+	"ecommerce/internal/domain/tax"
+)
 
 type Cart struct {
-	ID        int64      `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID    int64      `json:"user_id" gorm:"uniqueIndex;not null"`
-	CreatedAt time.Time  `json:"-" gorm:"autoCreateTime"`
-	UpdatedAt time.Time  `json:"-" gorm:"autoUpdateTime"`
-	Items     []CartItem `json:"items" gorm:"foreignKey:CartID"`
+	ID             int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID         *int64     `json:"user_id" gorm:"uniqueIndex"`     // nil for guest carts
+	GuestTokenHash *string    `json:"-" gorm:"type:text;uniqueIndex"` // set for guest carts
+	CouponID       *int64     `json:"-"`
+	CreatedAt      time.Time  `json:"-" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"-" gorm:"autoUpdateTime"`
+	Items          []CartItem `json:"items" gorm:"foreignKey:CartID"`
+	Totals         Totals     `json:"totals" gorm:"-"`
+
+	Coupon *AppliedCoupon `json:"coupon,omitempty" gorm:"-"`
 }
 
 func (Cart) TableName() string { return "carts" }
 
+// AppliedCoupon is the coupon attached to the cart and what it gives now
+type AppliedCoupon struct {
+	Code         string  `json:"code"`
+	Kind         string  `json:"kind"`
+	Discount     float64 `json:"discount"`
+	FreeShipping bool    `json:"free_shipping"`
+	Error        string  `json:"error,omitempty"` // why it currently gives nothing
+}
+
+// Totals is the server-side cart summary. Lines that cannot be bought
+// (unavailable or out of stock) are left out.
+type Totals struct {
+	ItemCount  int     `json:"item_count"`  // sum of quantities
+	Subtotal   float64 `json:"subtotal"`    // at list price
+	Discount   float64 `json:"discount"`    // variant discounts
+	ItemsTotal float64 `json:"items_total"` // subtotal - discount
+
+	PromotionDiscount float64 `json:"promotion_discount"` // bundles and quantity tiers
+	CouponDiscount    float64 `json:"coupon_discount"`    // applied after promotions
+	FreeShipping      bool    `json:"free_shipping"`
+
+	// Tax for TaxCountry/TaxRegion; empty when no destination is known.
+	// With TaxIncluded the prices above already contain it.
+	Tax         float64    `json:"tax"`
+	TaxIncluded bool       `json:"tax_included"`
+	TaxCountry  string     `json:"tax_country,omitempty"`
+	TaxRegion   string     `json:"tax_region,omitempty"`
+	TaxLines    []tax.Line `json:"tax_lines,omitempty"`
+
+	// items_total - promotion_discount - coupon_discount (+ tax unless included)
+	GrandTotal float64 `json:"grand_total"`
+}
+
 type CartItem struct {
-	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
-	CartID    int64     `json:"-" gorm:"not null;index;uniqueIndex:idx_cart_variant"`
-	VariantID int64     `json:"variant_id" gorm:"not null;uniqueIndex:idx_cart_variant"`
-	Qty       int       `json:"qty" gorm:"not null"`
-	CreatedAt time.Time `json:"-" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"-" gorm:"autoUpdateTime"`
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	CartID     int64     `json:"-" gorm:"not null;index;uniqueIndex:idx_cart_variant"`
+	VariantID  int64     `json:"variant_id" gorm:"not null;uniqueIndex:idx_cart_variant"`
+	Qty        int       `json:"qty" gorm:"not null"`
+	AddedPrice *float64  `json:"added_price,omitempty" gorm:"type:numeric(12,2)"` // unit price when added
+	CreatedAt  time.Time `json:"-" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"-" gorm:"autoUpdateTime"`
 
 	// Computed fields (populated via joins, not stored)
-	ProductID  int64   `json:"product_id" gorm:"-"`
-	Product    string  `json:"product" gorm:"-"`
-	Category   string  `json:"category" gorm:"-"`
-	TypeName   string  `json:"type" gorm:"-"`
-	Size       string  `json:"size" gorm:"-"`
-	Color      string  `json:"color" gorm:"-"`
-	Price      float64 `json:"price" gorm:"-"`
-	Discount   int     `json:"discount_percent" gorm:"-"`
-	FinalPrice float64 `json:"final_price" gorm:"-"`
-	StockQty   int     `json:"stock_qty" gorm:"-"`
+	ProductID  int64      `json:"product_id" gorm:"-"`
+	CategoryID int64      `json:"category_id" gorm:"-"`
+	Product    string     `json:"product" gorm:"-"`
+	Category   string     `json:"category" gorm:"-"`
+	TypeName   string     `json:"type" gorm:"-"`
+	Size       string     `json:"size" gorm:"-"`
+	Color      string     `json:"color" gorm:"-"`
+	Price      float64    `json:"price" gorm:"-"`
+	Discount   int        `json:"discount_percent" gorm:"-"` // effective, scheduled sales included
+	FinalPrice float64    `json:"final_price" gorm:"-"`
+	SaleEndsAt *time.Time `json:"sale_ends_at,omitempty" gorm:"-"`
+	StockQty   int        `json:"stock_qty" gorm:"-"`
+	Available  bool       `json:"available" gorm:"-"`  // product and category active
+	LineTotal  float64    `json:"line_total" gorm:"-"` // final_price * qty
+
+	// Per-unit parcel weight, summed for shipping quotes
+	WeightGrams int `json:"weight_grams" gorm:"-"`
+	// The product's tax class, which picks its tax rates
+	TaxClass string `json:"tax_class" gorm:"-"`
+
+	// Cart-level promotions applied to this line
+	Promotions        []AppliedPromotion `json:"promotions,omitempty" gorm:"-"`
+	PromotionDiscount float64            `json:"promotion_discount,omitempty" gorm:"-"`
+
+	// Set when final_price differs from added_price (PriceDropped/PriceIncreased)
+	PriceChange string  `json:"price_change,omitempty" gorm:"-"`
+	PriceDelta  float64 `json:"price_delta,omitempty" gorm:"-"` // final_price - added_price
+
+	// Problems the customer should see before checkout (Warn* codes)
+	Warnings []string `json:"warnings,omitempty" gorm:"-"`
 }
 
 func (CartItem) TableName() string { return "cart_items" }
+
+// AppliedPromotion is one promotion's effect on a cart line
+type AppliedPromotion struct {
+	PromotionID int64   `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Units       int     `json:"units"` // how many of the line's units it covers
+	Discount    float64 `json:"discount"`
+}
+
+// ShippingQuote lists the shipping options for the cart and a destination
+type ShippingQuote struct {
+	Zone         string            `json:"zone,omitempty"` // empty when nothing ships there
+	WeightGrams  int               `json:"weight_grams"`
+	CartValue    float64           `json:"cart_value"` // grand total before added tax; rates and thresholds use it
+	FreeShipping bool              `json:"free_shipping"`
+	Methods      []shipping.Option `json:"methods"`
+
+	// The cart's totals for this destination, same as GET /cart returns
+	CartTotals Totals `json:"cart_totals"`
+}
+
+// CartItem.PriceChange values
+const (
+	PriceDropped   = "dropped"
+	PriceIncreased = "increased"
+)
+
+// Cart line warnings
+const (
+	WarnUnavailable  = "product_unavailable" // product or category deactivated
+	WarnOutOfStock   = "out_of_stock"
+	WarnQtyReduced   = "qty_reduced" // qty lowered to the remaining stock
+	WarnPriceChanged = "price_changed"
+)
@@ -0,0 +1,43 @@
+package coupon
+
+import "time"
+
+const (
+	KindPercent      = "percent"
+	KindFixed        = "fixed"
+	KindFreeShipping = "free_shipping"
+)
+
+type Coupon struct {
+	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Code         string     `json:"code" gorm:"type:citext;uniqueIndex;not null"`
+	Description  string     `json:"description" gorm:"type:text;not null;default:''"`
+	Kind         string     `json:"kind" gorm:"type:text;not null"`
+	Value        float64    `json:"value" gorm:"type:numeric(12,2);not null;default:0"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+	MinCartValue float64    `json:"min_cart_value" gorm:"type:numeric(12,2);not null;default:0"`
+	IsActive     bool       `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Restrictions (loaded from coupon_categories / coupon_products)
+	CategoryIDs []int64 `json:"category_ids" gorm:"-"`
+	ProductIDs  []int64 `json:"product_ids" gorm:"-"`
+}
+
+func (Coupon) TableName() string { return "coupons" }
+
+type CouponCategory struct {
+	CouponID   int64 `gorm:"primaryKey"`
+	CategoryID int64 `gorm:"primaryKey"`
+}
+
+func (CouponCategory) TableName() string { return "coupon_categories" }
+
+type CouponProduct struct {
+	CouponID  int64 `gorm:"primaryKey"`
+	ProductID int64 `gorm:"primaryKey"`
+}
+
+func (CouponProduct) TableName() string { return "coupon_products" }
@@ -0,0 +1,54 @@
+package credit
+
+import "time"
+
+// Ledger reasons
+const (
+	ReasonIssue    = "issue"
+	ReasonAdjust   = "adjust"
+	ReasonRefund   = "refund"
+	ReasonTransfer = "transfer" // gift card balance moved into a wallet
+)
+
+type GiftCard struct {
+	ID             int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CodeHash       string     `json:"-" gorm:"type:text;uniqueIndex;not null"`
+	Last4          string     `json:"last4" gorm:"column:last4;type:text;not null"`
+	InitialBalance float64    `json:"initial_balance" gorm:"type:numeric(12,2);not null"`
+	Balance        float64    `json:"balance" gorm:"type:numeric(12,2);not null"`
+	RecipientEmail *string    `json:"recipient_email,omitempty" gorm:"type:citext"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	IsActive       bool       `json:"is_active" gorm:"not null;default:true"`
+	IssuedBy       *int64     `json:"issued_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (GiftCard) TableName() string { return "gift_cards" }
+
+// Usable reports whether the card can be redeemed at t
+func (g GiftCard) Usable(t time.Time) bool {
+	return g.IsActive && (g.ExpiresAt == nil || t.Before(*g.ExpiresAt))
+}
+
+type Wallet struct {
+	UserID    int64     `json:"user_id" gorm:"primaryKey"`
+	Balance   float64   `json:"balance" gorm:"type:numeric(12,2);not null;default:0"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Wallet) TableName() string { return "store_credit_wallets" }
+
+type LedgerEntry struct {
+	ID           int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	GiftCardID   *int64    `json:"gift_card_id,omitempty" gorm:"index"`
+	UserID       *int64    `json:"user_id,omitempty" gorm:"index"`
+	Amount       float64   `json:"amount" gorm:"type:numeric(12,2);not null"`
+	BalanceAfter float64   `json:"balance_after" gorm:"type:numeric(12,2);not null"`
+	Reason       string    `json:"reason" gorm:"type:text;not null"`
+	Reference    string    `json:"reference" gorm:"type:text;not null;default:''"`
+	ActorID      *int64    `json:"actor_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (LedgerEntry) TableName() string { return "credit_ledger" }
@@ -0,0 +1,22 @@
+package export
+
+import "time"
+
+const (
+	StatusPending = "pending"
+	StatusReady   = "ready"
+	StatusFailed  = "failed"
+)
+
+type DataExport struct {
+	ID          int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      int64      `json:"-" gorm:"not null;index"`
+	Status      string     `json:"status" gorm:"type:text;not null;default:'pending'"`
+	FilePath    string     `json:"-" gorm:"type:text"`
+	Error       string     `json:"-" gorm:"type:text"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (DataExport) TableName() string { return "data_exports" }
@@ -0,0 +1,29 @@
+package pricing
+
+import "time"
+
+// PriceRule targets
+const (
+	TargetCategory    = "category"
+	TargetProductType = "product_type"
+	TargetProduct     = "product"
+	TargetVariant     = "variant"
+)
+
+// PriceRule is a scheduled sale. Which rule wins for a variant is defined
+// by the variant_prices view (migrations/018_price_rules.sql).
+type PriceRule struct {
+	ID         int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name       string     `json:"name" gorm:"type:text;not null"`
+	TargetType string     `json:"target_type" gorm:"type:text;not null"`
+	TargetID   int64      `json:"target_id" gorm:"not null"`
+	PercentOff int        `json:"percent_off" gorm:"not null"`
+	StartsAt   time.Time  `json:"starts_at" gorm:"not null"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+	IsActive   bool       `json:"is_active" gorm:"not null;default:true"`
+	CreatedBy  *int64     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (PriceRule) TableName() string { return "price_rules" }
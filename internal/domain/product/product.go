@@ -20,25 +20,33 @@ type Product struct {
 	Name        string    `json:"name" gorm:"type:text;not null"`
 	Description string    `json:"description,omitempty" gorm:"type:text"`
 	IsActive    bool      `json:"is_active" gorm:"not null;default:true"`
+	TaxClass    string    `json:"tax_class" gorm:"type:text;not null;default:'standard'"`
 	CreatedBy   *int64    `json:"created_by,omitempty" gorm:"index"`
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 	Variants    []Variant `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+
+	// Computed for listings (lowest current variant price, any sale running)
+	PriceFrom *float64 `json:"price_from,omitempty" gorm:"-"`
+	OnSale    bool     `json:"on_sale" gorm:"-"`
 }
 
 func (Product) TableName() string { return "products" }
 
 type Variant struct {
-	ID              int64     `json:"id" gorm:"primaryKey;autoIncrement"`
-	ProductID       int64     `json:"product_id" gorm:"not null;index"`
-	Size            string    `json:"size" gorm:"type:text;not null"`
-	Color           string    `json:"color" gorm:"type:text;not null"`
-	Price           float64   `json:"price" gorm:"type:numeric(12,2);not null"`
-	DiscountPercent int       `json:"discount_percent" gorm:"not null;default:0"`
-	FinalPrice      float64   `json:"final_price" gorm:"-"` // computed
-	StockQty        int       `json:"stock_qty" gorm:"not null;default:0"`
-	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID              int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID       int64      `json:"product_id" gorm:"not null;index"`
+	Size            string     `json:"size" gorm:"type:text;not null"`
+	Color           string     `json:"color" gorm:"type:text;not null"`
+	Price           float64    `json:"price" gorm:"type:numeric(12,2);not null"`
+	DiscountPercent int        `json:"discount_percent" gorm:"not null;default:0"`
+	FinalPrice      float64    `json:"final_price" gorm:"-"` // computed
+	OnSale          bool       `json:"on_sale" gorm:"-"`     // a price rule applies now
+	SaleEndsAt      *time.Time `json:"sale_ends_at,omitempty" gorm:"-"`
+	StockQty        int        `json:"stock_qty" gorm:"not null;default:0"`
+	WeightGrams     int        `json:"weight_grams" gorm:"not null;default:0"` // parcel weight for shipping
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 func (Variant) TableName() string { return "product_variants" }
@@ -0,0 +1,43 @@
+package promotion
+
+import "time"
+
+const (
+	KindQuantityTier = "quantity_tier"
+	KindBundle       = "bundle"
+)
+
+// Quantity tier targets
+const (
+	TargetCategory = "category"
+	TargetProduct  = "product"
+	TargetVariant  = "variant"
+)
+
+type Promotion struct {
+	ID         int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name       string     `json:"name" gorm:"type:text;not null"`
+	Kind       string     `json:"kind" gorm:"type:text;not null"`
+	PercentOff int        `json:"percent_off" gorm:"not null"`
+	TargetType *string    `json:"target_type,omitempty" gorm:"type:text"`
+	TargetID   *int64     `json:"target_id,omitempty"`
+	MinQty     *int       `json:"min_qty,omitempty"`
+	Priority   int        `json:"priority" gorm:"not null;default:0"`
+	StartsAt   *time.Time `json:"starts_at,omitempty"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+	IsActive   bool       `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	BundleItems []BundleItem `json:"bundle_items,omitempty" gorm:"foreignKey:PromotionID"`
+}
+
+func (Promotion) TableName() string { return "promotions" }
+
+type BundleItem struct {
+	PromotionID int64 `json:"-" gorm:"primaryKey"`
+	ProductID   int64 `json:"product_id" gorm:"primaryKey"`
+	Qty         int   `json:"qty" gorm:"not null;default:1"`
+}
+
+func (BundleItem) TableName() string { return "promotion_bundle_items" }
@@ -0,0 +1,17 @@
+package restock
+
+import "time"
+
+// Subscription asks for one email when a sold-out variant is back in stock
+type Subscription struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	VariantID int64     `json:"variant_id" gorm:"not null;uniqueIndex:idx_variant_email"`
+	UserID    *int64    `json:"-" gorm:"index"`
+	Email     string    `json:"email" gorm:"type:citext;not null;uniqueIndex:idx_variant_email"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Guests confirm through an emailed link before anything else is sent
+	ConfirmedAt *time.Time `json:"-"`
+}
+
+func (Subscription) TableName() string { return "stock_notifications" }
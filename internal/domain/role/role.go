@@ -0,0 +1,57 @@
+package role
+
+import "time"
+
+// Built-in roles (more can be created by admins)
+const (
+	User           = "user"
+	Admin          = "admin"
+	CatalogEditor  = "catalog_editor"
+	InventoryClerk = "inventory_clerk"
+	SupportAgent   = "support_agent"
+)
+
+// Permission codes checked by auth.RequirePermission
+const (
+	PermAdminAccess     = "admin:access"
+	PermCategoriesWrite = "categories:write"
+	PermProductsWrite   = "products:write"
+	PermInventoryWrite  = "inventory:write"
+	PermUsersRead       = "users:read"
+	PermUsersWrite      = "users:write"
+	PermRolesManage     = "roles:manage"
+	PermSecurityManage  = "security:manage"
+	PermAuditRead       = "audit:read"
+	PermPromotionsWrite = "promotions:write"
+	PermCreditManage    = "credit:manage"
+	PermShippingManage  = "shipping:manage"
+	PermFulfillment     = "fulfillment:manage"
+	PermTaxManage       = "tax:manage"
+)
+
+type Role struct {
+	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"type:text;uniqueIndex;not null"`
+	Description string    `json:"description" gorm:"type:text;not null;default:''"`
+	IsSystem    bool      `json:"is_system" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	Permissions []string  `json:"permissions" gorm:"-"` // loaded via role_permissions
+}
+
+func (Role) TableName() string { return "roles" }
+
+type Permission struct {
+	ID          int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	Code        string `json:"code" gorm:"type:text;uniqueIndex;not null"`
+	Description string `json:"description" gorm:"type:text;not null;default:''"`
+}
+
+func (Permission) TableName() string { return "permissions" }
+
+type RolePermission struct {
+	RoleID       int64 `gorm:"primaryKey"`
+	PermissionID int64 `gorm:"primaryKey"`
+}
+
+func (RolePermission) TableName() string { return "role_permissions" }
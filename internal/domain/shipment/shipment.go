@@ -0,0 +1,89 @@
+package shipment
+
+import "time"
+
+// Shipment statuses, in the order a parcel normally goes through them
+const (
+	StatusPending        = "pending"
+	StatusShipped        = "shipped"
+	StatusInTransit      = "in_transit"
+	StatusOutForDelivery = "out_for_delivery"
+	StatusDelivered      = "delivered"
+	StatusException      = "exception" // delayed, damaged, address problem
+	StatusReturned       = "returned"
+)
+
+// ValidStatus reports whether s is a known shipment status
+func ValidStatus(s string) bool {
+	switch s {
+	case StatusPending, StatusShipped, StatusInTransit, StatusOutForDelivery,
+		StatusDelivered, StatusException, StatusReturned:
+		return true
+	}
+	return false
+}
+
+type Shipment struct {
+	ID             int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID         int64      `json:"user_id" gorm:"not null;index"`
+	OrderReference string     `json:"order_reference" gorm:"type:text;not null;default:''"`
+	Carrier        string     `json:"carrier" gorm:"type:text;not null;default:''"`
+	TrackingNumber *string    `json:"tracking_number,omitempty" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"type:text;not null;default:'pending'"`
+	ShippedAt      *time.Time `json:"shipped_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedBy      *int64     `json:"-"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// copied from the customer's address book when the shipment is
+	// created, so later edits or deletion don't change where it went
+	ShipTo Address `json:"ship_to" gorm:"embedded;embeddedPrefix:ship_to_"`
+
+	Items  []Item  `json:"items" gorm:"foreignKey:ShipmentID"`
+	Events []Event `json:"events" gorm:"foreignKey:ShipmentID"`
+
+	TrackingURL string `json:"tracking_url,omitempty" gorm:"-"` // from the carrier
+}
+
+func (Shipment) TableName() string { return "shipments" }
+
+// Address is the delivery address as it was when the shipment was created
+type Address struct {
+	FullName   string `json:"full_name" gorm:"type:text;not null;default:''"`
+	Company    string `json:"company" gorm:"type:text;not null;default:''"`
+	Line1      string `json:"line1" gorm:"column:line1;type:text;not null;default:''"`
+	Line2      string `json:"line2" gorm:"column:line2;type:text;not null;default:''"`
+	City       string `json:"city" gorm:"type:text;not null;default:''"`
+	Region     string `json:"region" gorm:"type:text;not null;default:''"`
+	PostalCode string `json:"postal_code" gorm:"type:text;not null;default:''"`
+	Country    string `json:"country" gorm:"type:text;not null;default:''"`
+	Phone      string `json:"phone" gorm:"type:text;not null;default:''"`
+}
+
+type Item struct {
+	ID         int64 `json:"id" gorm:"primaryKey;autoIncrement"`
+	ShipmentID int64 `json:"-" gorm:"not null;index"`
+	VariantID  int64 `json:"variant_id" gorm:"not null"`
+	Qty        int   `json:"qty" gorm:"not null"`
+
+	// Computed fields (populated via joins, not stored)
+	ProductID int64  `json:"product_id" gorm:"-"`
+	Product   string `json:"product" gorm:"-"`
+	Size      string `json:"size" gorm:"-"`
+	Color     string `json:"color" gorm:"-"`
+}
+
+func (Item) TableName() string { return "shipment_items" }
+
+type Event struct {
+	ID          int64     `json:"-" gorm:"primaryKey;autoIncrement"`
+	ShipmentID  int64     `json:"-" gorm:"not null;index"`
+	Status      string    `json:"status" gorm:"type:text;not null"`
+	Description string    `json:"description" gorm:"type:text;not null;default:''"`
+	Location    string    `json:"location" gorm:"type:text;not null;default:''"`
+	OccurredAt  time.Time `json:"occurred_at" gorm:"not null"`
+	CreatedAt   time.Time `json:"-" gorm:"autoCreateTime"`
+}
+
+func (Event) TableName() string { return "shipment_events" }
@@ -0,0 +1,78 @@
+package shipping
+
+import "time"
+
+const (
+	KindStandard = "standard"
+	KindExpress  = "express"
+	KindPickup   = "pickup"
+)
+
+// Method.Basis values
+const (
+	BasisWeight = "weight" // rates keyed on parcel grams
+	BasisValue  = "value"  // rates keyed on cart value
+)
+
+// AnyCountry in an area matches every destination
+const AnyCountry = "*"
+
+type Zone struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"type:text;not null"`
+	IsActive  bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Areas []Area `json:"areas" gorm:"foreignKey:ZoneID"`
+}
+
+func (Zone) TableName() string { return "shipping_zones" }
+
+type Area struct {
+	ZoneID  int64  `json:"-" gorm:"primaryKey"`
+	Country string `json:"country" gorm:"primaryKey;type:text"`
+	Region  string `json:"region" gorm:"primaryKey;type:text;default:''"` // '' = whole country
+}
+
+func (Area) TableName() string { return "shipping_zone_areas" }
+
+type Method struct {
+	ID            int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ZoneID        int64     `json:"zone_id" gorm:"not null;index"`
+	Name          string    `json:"name" gorm:"type:text;not null"`
+	Kind          string    `json:"kind" gorm:"type:text;not null"`
+	Basis         string    `json:"basis" gorm:"type:text;not null"`
+	FreeThreshold *float64  `json:"free_threshold,omitempty" gorm:"type:numeric(12,2)"`
+	MinDays       int       `json:"min_days" gorm:"not null;default:0"`
+	MaxDays       int       `json:"max_days" gorm:"not null;default:0"`
+	IsActive      bool      `json:"is_active" gorm:"not null;default:true"`
+	SortOrder     int       `json:"sort_order" gorm:"not null;default:0"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Rates []Rate `json:"rates" gorm:"foreignKey:MethodID"`
+}
+
+func (Method) TableName() string { return "shipping_methods" }
+
+type Rate struct {
+	ID       int64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	MethodID int64    `json:"-" gorm:"not null;index"`
+	MinValue float64  `json:"min_value" gorm:"type:numeric(12,2);not null;default:0"`
+	MaxValue *float64 `json:"max_value,omitempty" gorm:"type:numeric(12,2)"` // exclusive; nil = no limit
+	Price    float64  `json:"price" gorm:"type:numeric(12,2);not null"`
+}
+
+func (Rate) TableName() string { return "shipping_rates" }
+
+// Option is one method offered for a destination, priced for the cart
+type Option struct {
+	MethodID int64   `json:"method_id"`
+	Name     string  `json:"name"`
+	Kind     string  `json:"kind"`
+	Price    float64 `json:"price"`
+	Free     bool    `json:"free"` // threshold reached or free-shipping coupon
+	MinDays  int     `json:"min_days"`
+	MaxDays  int     `json:"max_days"`
+}
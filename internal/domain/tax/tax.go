@@ -0,0 +1,29 @@
+package tax
+
+import "time"
+
+// ClassStandard is the tax class products get unless set otherwise
+const ClassStandard = "standard"
+
+type Rate struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Country   string    `json:"country" gorm:"type:char(2);not null"`
+	Region    string    `json:"region" gorm:"type:text;not null;default:''"` // '' = whole country
+	TaxClass  string    `json:"tax_class" gorm:"type:text;not null;default:'standard'"`
+	Name      string    `json:"name" gorm:"type:text;not null"`
+	Rate      float64   `json:"rate" gorm:"type:numeric(7,4);not null"` // percent
+	IsActive  bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Rate) TableName() string { return "tax_rates" }
+
+// Line is one entry of a tax breakdown
+type Line struct {
+	Name     string  `json:"name"`
+	TaxClass string  `json:"tax_class"`
+	Rate     float64 `json:"rate"`    // percent
+	Taxable  float64 `json:"taxable"` // net amount the rate was applied to
+	Amount   float64 `json:"amount"`
+}
@@ -0,0 +1,41 @@
+package wishlist
+
+import "time"
+
+const (
+	KindWishlist      = "wishlist"
+	KindSavedForLater = "saved_for_later"
+)
+
+type Wishlist struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     int64     `json:"-" gorm:"not null;index"`
+	Name       string    `json:"name" gorm:"type:text;not null"`
+	Kind       string    `json:"kind" gorm:"type:text;not null;default:'wishlist'"`
+	ShareToken *string   `json:"share_token,omitempty" gorm:"type:text;uniqueIndex"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	Items      []Item    `json:"items,omitempty" gorm:"foreignKey:WishlistID"`
+
+	ItemCount int `json:"item_count" gorm:"-"` // computed
+}
+
+func (Wishlist) TableName() string { return "wishlists" }
+
+type Item struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	WishlistID int64     `json:"-" gorm:"not null;index"`
+	ProductID  int64     `json:"product_id" gorm:"not null"`
+	VariantID  *int64    `json:"variant_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Computed fields (populated via joins, not stored)
+	Product    string   `json:"product" gorm:"-"`
+	Size       *string  `json:"size,omitempty" gorm:"-"`
+	Color      *string  `json:"color,omitempty" gorm:"-"`
+	FinalPrice *float64 `json:"final_price,omitempty" gorm:"-"` // variant entries only
+	StockQty   *int     `json:"stock_qty,omitempty" gorm:"-"`
+	Available  bool     `json:"available" gorm:"-"`
+}
+
+func (Item) TableName() string { return "wishlist_items" }
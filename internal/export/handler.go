@@ -0,0 +1,172 @@
+package export
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/auth"
+	exportDomain "ecommerce/internal/domain/export"
+	"ecommerce/internal/mail"
+	"ecommerce/internal/util"
+)
+
+type Config struct {
+	Dir           string        // where archives are written
+	LinkTTL       time.Duration // how long the download link works
+	BuildTimeout  time.Duration // a build still pending after this is failed
+	SigningSecret string
+	BaseURL       string // public API base, e.g. http://localhost:8080
+}
+
+type Handler struct {
+	repo   *Repo
+	mailer mail.Mailer
+	cfg    Config
+}
+
+func NewHandler(repo *Repo, mailer mail.Mailer, cfg Config) *Handler {
+	return &Handler{repo: repo, mailer: mailer, cfg: cfg}
+}
+
+// Request starts building an archive in the background; the link is emailed
+func (h *Handler) Request(c *gin.Context) {
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	pending, err := h.repo.HasPending(userID, time.Now().Add(-h.cfg.BuildTimeout))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start export"})
+		return
+	}
+	if pending {
+		c.JSON(http.StatusConflict, gin.H{"error": "an export is already in progress"})
+		return
+	}
+
+	e, err := h.repo.Create(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start export"})
+		return
+	}
+	go h.build(e)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"ok":      true,
+		"export":  e,
+		"message": "We're preparing your data. A download link will be emailed to you.",
+	})
+}
+
+// List shows the user's exports and their status
+func (h *Handler) List(c *gin.Context) {
+	userID := c.GetInt64(auth.CtxUserIDKey)
+	items, err := h.repo.ListByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list exports"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Download serves the archive for a valid signed link (no login needed)
+func (h *Handler) Download(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+
+	if !util.VerifyExpiring(h.cfg.SigningSecret, exportResource(id), expires, c.Query("sig")) {
+		c.JSON(http.StatusGone, gin.H{"error": "link invalid or expired"})
+		return
+	}
+
+	e, err := h.repo.ByID(id)
+	if err != nil || e.Status != exportDomain.StatusReady || e.FilePath == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "export not found"})
+		return
+	}
+	c.FileAttachment(e.FilePath, fmt.Sprintf("my-data-%d.zip", e.ID))
+}
+
+func (h *Handler) build(e exportDomain.DataExport) {
+	defer func() {
+		if r := recover(); r != nil {
+			_ = h.repo.MarkFailed(e.ID, fmt.Sprint(r))
+		}
+	}()
+
+	data, err := h.repo.Collect(e.UserID)
+	if err != nil {
+		_ = h.repo.MarkFailed(e.ID, err.Error())
+		return
+	}
+
+	path := filepath.Join(h.cfg.Dir, fmt.Sprintf("export-%d-%d.zip", e.UserID, e.ID))
+	if err := writeArchive(path, e.UserID, data); err != nil {
+		_ = os.Remove(path)
+		_ = h.repo.MarkFailed(e.ID, err.Error())
+		return
+	}
+
+	expiresAt := time.Now().Add(h.cfg.LinkTTL)
+	if err := h.repo.MarkReady(e.ID, path, expiresAt); err != nil {
+		_ = os.Remove(path)
+		return
+	}
+
+	email, err := h.repo.EmailOf(e.UserID)
+	if err != nil {
+		return
+	}
+	link := fmt.Sprintf("%s/api/exports/%d/download?expires=%d&sig=%s",
+		h.cfg.BaseURL, e.ID, expiresAt.Unix(), util.SignExpiring(h.cfg.SigningSecret, exportResource(e.ID), expiresAt))
+	body := "Your personal data export is ready.\n\n" +
+		"Download it here: " + link + "\n\n" +
+		"The link expires at: " + expiresAt.Format(time.RFC1123) + "\n\n" +
+		"If you didn't request this, please contact support."
+	if err := h.mailer.Send(email, "Your data export is ready", body); err != nil {
+		log.Printf("export %d: email failed: %v", e.ID, err)
+	}
+}
+
+// StartCleanup fails abandoned builds and deletes expired archives now
+// and then every interval, in the background
+func (h *Handler) StartCleanup(interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			h.cleanup()
+			<-t.C
+		}
+	}()
+}
+
+func (h *Handler) cleanup() {
+	if err := h.repo.FailStale(time.Now().Add(-h.cfg.BuildTimeout)); err != nil {
+		log.Printf("export cleanup: %v", err)
+	}
+	h.removeExpired()
+}
+
+// removeExpired deletes archives whose link has expired
+func (h *Handler) removeExpired() {
+	expired, err := h.repo.ListExpired()
+	if err != nil {
+		log.Printf("export cleanup: %v", err)
+		return
+	}
+	for _, e := range expired {
+		if err := os.Remove(e.FilePath); err == nil || os.IsNotExist(err) {
+			_ = h.repo.ClearFile(e.ID)
+		}
+	}
+}
+
+func exportResource(id int64) string {
+	return "export:" + strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,181 @@
+package export
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	exportDomain "ecommerce/internal/domain/export"
+)
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) Create(userID int64) (exportDomain.DataExport, error) {
+	e := exportDomain.DataExport{UserID: userID, Status: exportDomain.StatusPending}
+	err := r.db.Create(&e).Error
+	return e, err
+}
+
+func (r *Repo) ByID(id int64) (exportDomain.DataExport, error) {
+	var e exportDomain.DataExport
+	err := r.db.First(&e, id).Error
+	return e, err
+}
+
+func (r *Repo) ListByUser(userID int64) ([]exportDomain.DataExport, error) {
+	var out []exportDomain.DataExport
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&out).Error
+	return out, err
+}
+
+// HasPending reports an export still being built; ones started before
+// since are treated as abandoned
+func (r *Repo) HasPending(userID int64, since time.Time) (bool, error) {
+	var n int64
+	err := r.db.Model(&exportDomain.DataExport{}).
+		Where("user_id = ? AND status = ? AND created_at >= ?", userID, exportDomain.StatusPending, since).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// FailStale marks exports still pending since before as failed (their
+// build died, e.g. with a restart)
+func (r *Repo) FailStale(before time.Time) error {
+	now := time.Now()
+	return r.db.Model(&exportDomain.DataExport{}).
+		Where("status = ? AND created_at < ?", exportDomain.StatusPending, before).
+		Updates(map[string]interface{}{
+			"status":       exportDomain.StatusFailed,
+			"error":        "build interrupted",
+			"completed_at": now,
+		}).Error
+}
+
+func (r *Repo) MarkReady(id int64, path string, expiresAt time.Time) error {
+	now := time.Now()
+	return r.db.Model(&exportDomain.DataExport{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       exportDomain.StatusReady,
+		"file_path":    path,
+		"expires_at":   expiresAt,
+		"completed_at": now,
+	}).Error
+}
+
+func (r *Repo) MarkFailed(id int64, msg string) error {
+	now := time.Now()
+	return r.db.Model(&exportDomain.DataExport{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       exportDomain.StatusFailed,
+		"error":        msg,
+		"completed_at": now,
+	}).Error
+}
+
+// ListExpired returns ready exports whose link has expired and file still exists
+func (r *Repo) ListExpired() ([]exportDomain.DataExport, error) {
+	var out []exportDomain.DataExport
+	err := r.db.Where("status = ? AND expires_at < ? AND file_path <> ''", exportDomain.StatusReady, time.Now()).
+		Find(&out).Error
+	return out, err
+}
+
+func (r *Repo) ClearFile(id int64) error {
+	return r.db.Model(&exportDomain.DataExport{}).Where("id = ?", id).Update("file_path", "").Error
+}
+
+// section is one JSON file in the archive
+type section struct {
+	name  string
+	query func(db *gorm.DB, userID int64) *gorm.DB
+}
+
+// sections lists every kind of personal record we hold. Secrets (password,
+// token and OTP hashes, TOTP secret) are deliberately left out.
+var sections = []section{
+	{"account", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("users").
+			Select("id, email, role, is_active, email_verified, display_name, phone, totp_enabled, created_at, updated_at").
+			Where("id = ?", uid)
+	}},
+	{"sessions", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("refresh_tokens").
+			Select("id, created_at, expires_at, revoked_at").
+			Where("user_id = ?", uid).Order("id")
+	}},
+	{"devices", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("user_known_devices").
+			Select("ip, user_agent, first_seen_at, last_seen_at").
+			Where("user_id = ?", uid).Order("id")
+	}},
+	{"linked_identities", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("user_identities").
+			Select("provider, subject, email, created_at").
+			Where("user_id = ?", uid).Order("id")
+	}},
+	{"addresses", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("addresses").
+			Select("label, full_name, company, line1, line2, city, region, postal_code, country, phone, is_default_shipping, is_default_billing, created_at").
+			Where("user_id = ?", uid).Order("id")
+	}},
+	{"cart", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("cart_items ci").
+			Select("ci.variant_id, p.name as product, v.size, v.color, ci.qty, ci.created_at").
+			Joins("JOIN carts c ON c.id = ci.cart_id").
+			Joins("JOIN product_variants v ON v.id = ci.variant_id").
+			Joins("JOIN products p ON p.id = v.product_id").
+			Where("c.user_id = ?", uid).Order("ci.id")
+	}},
+	{"wishlists", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("wishlist_items wi").
+			Select("w.name as wishlist, w.kind, wi.product_id, p.name as product, wi.variant_id, wi.created_at").
+			Joins("JOIN wishlists w ON w.id = wi.wishlist_id").
+			Joins("JOIN products p ON p.id = wi.product_id").
+			Where("w.user_id = ?", uid).Order("w.id, wi.id")
+	}},
+	{"stock_notifications", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("stock_notifications s").
+			Select("s.variant_id, p.name as product, v.size, v.color, s.email, s.created_at").
+			Joins("JOIN product_variants v ON v.id = s.variant_id").
+			Joins("JOIN products p ON p.id = v.product_id").
+			Where("s.user_id = ?", uid).Order("s.id")
+	}},
+	{"shipments", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("shipments").
+			Select("id, order_reference, carrier, tracking_number, status, shipped_at, delivered_at, created_at").
+			Where("user_id = ?", uid).Order("id")
+	}},
+	{"store_credit", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("credit_ledger").
+			Select("amount, balance_after, reason, reference, created_at").
+			Where("user_id = ?", uid).Order("id")
+	}},
+	{"account_history", func(db *gorm.DB, uid int64) *gorm.DB {
+		return db.Table("audit_log").
+			Select("action, details, created_at").
+			Where("target_type = 'user' AND target_id = ?", uid).Order("id")
+	}},
+}
+
+// Collect loads every section for the user
+func (r *Repo) Collect(userID int64) (map[string][]map[string]interface{}, error) {
+	out := map[string][]map[string]interface{}{}
+	for _, s := range sections {
+		rows := []map[string]interface{}{}
+		if err := s.query(r.db, userID).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		out[s.name] = rows
+	}
+	return out, nil
+}
+
+func (r *Repo) EmailOf(userID int64) (string, error) {
+	var email string
+	err := r.db.Table("users").Select("email").Where("id = ?", userID).Row().Scan(&email)
+	return email, err
+}
@@ -3,10 +3,11 @@ package models
 import "time"
 
 type Cart struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement"`
-	UserID    int64     `gorm:"uniqueIndex;not null"`
-	CreatedAt time.Time `gorm:"not null;default:now()"`
-	UpdatedAt time.Time `gorm:"not null;default:now()"`
+	ID             int64     `gorm:"primaryKey;autoIncrement"`
+	UserID         *int64    `gorm:"uniqueIndex"` // nil for guest carts (013_guest_carts.sql)
+	GuestTokenHash *string   `gorm:"uniqueIndex"`
+	CreatedAt      time.Time `gorm:"not null;default:now()"`
+	UpdatedAt      time.Time `gorm:"not null;default:now()"`
 }
 
 func (Cart) TableName() string { return "carts" }
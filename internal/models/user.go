@@ -6,7 +6,7 @@ type User struct {
 	ID           int64     `gorm:"primaryKey;autoIncrement"`
 	Email        string    `gorm:"type:citext;uniqueIndex;not null"`
 	PasswordHash string    `gorm:"not null"`
-	Role         string    `gorm:"not null"` // user/admin (migration enforces check)
+	Role         string    `gorm:"not null"` // references roles(name), see 009_rbac.sql
 	IsActive     bool      `gorm:"not null;default:true"`
 	EmailVerified bool     `gorm:"not null;default:false"` // added by 002_otp.sql
 	CreatedAt    time.Time `gorm:"not null;default:now()"`
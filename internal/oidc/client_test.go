@@ -0,0 +1,199 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockProvider is a minimal OpenID provider: discovery, JWKS and a token
+// endpoint that returns whatever ID token the test prepared.
+type mockProvider struct {
+	srv     *httptest.Server
+	key     *rsa.PrivateKey
+	kid     string
+	idToken string
+	form    map[string]string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &mockProvider{key: key, kid: "k1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Discovery{
+			Issuer:                p.srv.URL,
+			AuthorizationEndpoint: p.srv.URL + "/authorize",
+			TokenEndpoint:         p.srv.URL + "/token",
+			JWKSURI:               p.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []jwk{{
+			Kty: "RSA",
+			Kid: p.kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "authorization_code" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		p.form = map[string]string{}
+		for k := range r.Form {
+			p.form[k] = r.Form.Get(k)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": p.idToken})
+	})
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+func (p *mockProvider) client() *Client {
+	return NewClient(ProviderConfig{
+		Name:        "mock",
+		Issuer:      p.srv.URL,
+		ClientID:    "shop",
+		RedirectURL: "http://localhost/callback",
+	}, p.srv.Client())
+}
+
+func (p *mockProvider) sign(t *testing.T, kid string, claims IDClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(p.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func (p *mockProvider) claims(nonce, audience string) IDClaims {
+	now := time.Now()
+	return IDClaims{
+		Nonce:         nonce,
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.srv.URL,
+			Subject:   "user-123",
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	}
+}
+
+func TestExchange(t *testing.T) {
+	p := newMockProvider(t)
+	p.idToken = p.sign(t, p.kid, p.claims("n-1", "shop"))
+
+	idc, err := p.client().Exchange(context.Background(), "code-1", "verifier-1", "n-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if idc.Subject != "user-123" || idc.Email != "jane@example.com" || !idc.EmailVerified {
+		t.Errorf("unexpected claims: %+v", idc)
+	}
+	if p.form["code"] != "code-1" || p.form["code_verifier"] != "verifier-1" || p.form["client_id"] != "shop" {
+		t.Errorf("unexpected token request: %v", p.form)
+	}
+}
+
+func TestExchangeRejectsBadIDTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		token func(p *mockProvider) string
+	}{
+		{"bad nonce", func(p *mockProvider) string {
+			return p.sign(t, p.kid, p.claims("other-nonce", "shop"))
+		}},
+		{"wrong audience", func(p *mockProvider) string {
+			return p.sign(t, p.kid, p.claims("n-1", "another-client"))
+		}},
+		{"unknown kid", func(p *mockProvider) string {
+			return p.sign(t, "k2", p.claims("n-1", "shop"))
+		}},
+		{"wrong issuer", func(p *mockProvider) string {
+			c := p.claims("n-1", "shop")
+			c.Issuer = "https://evil.example"
+			return p.sign(t, p.kid, c)
+		}},
+		{"expired", func(p *mockProvider) string {
+			c := p.claims("n-1", "shop")
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			return p.sign(t, p.kid, c)
+		}},
+		{"unsigned", func(p *mockProvider) string {
+			s, _ := jwt.NewWithClaims(jwt.SigningMethodNone, p.claims("n-1", "shop")).
+				SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return s
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newMockProvider(t)
+			p.idToken = tt.token(p)
+			if _, err := p.client().Exchange(context.Background(), "code-1", "verifier-1", "n-1"); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenPicksUpRotatedKey(t *testing.T) {
+	p := newMockProvider(t)
+	c := p.client()
+	if _, err := c.VerifyIDToken(context.Background(), p.sign(t, p.kid, p.claims("n", "shop")), "n"); err != nil {
+		t.Fatalf("first key: %v", err)
+	}
+
+	// provider rotates to a new key id; the cached JWKS is refetched on a miss
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.key, p.kid = key, "k2"
+	if _, err := c.VerifyIDToken(context.Background(), p.sign(t, "k2", p.claims("n", "shop")), "n"); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	p := newMockProvider(t)
+	u, err := p.client().AuthCodeURL(context.Background(), "st", "nc", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		p.srv.URL + "/authorize?",
+		"code_challenge=" + CodeChallenge("verifier-1"),
+		"code_challenge_method=S256",
+		"state=st",
+		"nonce=nc",
+	} {
+		if !strings.Contains(u, want) {
+			t.Errorf("auth URL %q missing %q", u, want)
+		}
+	}
+}
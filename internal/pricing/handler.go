@@ -0,0 +1,142 @@
+package pricing
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/audit"
+	"ecommerce/internal/auth"
+	"ecommerce/internal/domain/pricing"
+)
+
+type Handler struct {
+	repo *Repo
+}
+
+func NewHandler(repo *Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+type PriceRuleReq struct {
+	Name       string     `json:"name" binding:"required"`
+	TargetType string     `json:"target_type" binding:"required"`
+	TargetID   int64      `json:"target_id" binding:"required"`
+	PercentOff int        `json:"percent_off" binding:"required"`
+	StartsAt   time.Time  `json:"starts_at" binding:"required"`
+	EndsAt     *time.Time `json:"ends_at"`
+	IsActive   *bool      `json:"is_active"`
+}
+
+func (req PriceRuleReq) toRule() (pricing.PriceRule, string) {
+	pr := pricing.PriceRule{
+		Name:       strings.TrimSpace(req.Name),
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		PercentOff: req.PercentOff,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+	}
+	switch {
+	case pr.Name == "":
+		return pr, "name is required"
+	case targetTables[pr.TargetType] == "":
+		return pr, "target_type must be category, product_type, product or variant"
+	case pr.PercentOff <= 0 || pr.PercentOff > 100:
+		return pr, "percent_off must be between 1 and 100"
+	case pr.EndsAt != nil && !pr.EndsAt.After(pr.StartsAt):
+		return pr, "ends_at must be after starts_at"
+	}
+	return pr, ""
+}
+
+// Admin: list price rules (?live=true for rules in effect now)
+func (h *Handler) AdminList(c *gin.Context) {
+	page, pageSize := audit.Pagination(c)
+	items, total, err := h.repo.List(c.Query("live") == "true", pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list price rules"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total, "page": page, "page_size": pageSize})
+}
+
+func (h *Handler) AdminGet(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	pr, err := h.repo.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "price rule not found"})
+		return
+	}
+	c.JSON(http.StatusOK, pr)
+}
+
+func (h *Handler) AdminCreate(c *gin.Context) {
+	var req PriceRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	pr, msg := req.toRule()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+	pr.CreatedBy = &userID
+
+	if err := h.repo.Create(&pr); err != nil {
+		respondError(c, err, "failed to create price rule")
+		return
+	}
+	c.JSON(http.StatusCreated, pr)
+}
+
+// AdminUpdate replaces the rule definition (PUT semantics)
+func (h *Handler) AdminUpdate(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req PriceRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	pr, msg := req.toRule()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	pr.ID = id
+
+	if err := h.repo.Update(&pr); err != nil {
+		respondError(c, err, "failed to update price rule")
+		return
+	}
+	pr, _ = h.repo.ByID(id)
+	c.JSON(http.StatusOK, pr)
+}
+
+func (h *Handler) AdminDelete(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.repo.Delete(id); err != nil {
+		respondError(c, err, "failed to delete price rule")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "price rule not found"})
+	case errors.Is(err, ErrTargetNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,103 @@
+package pricing
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"ecommerce/internal/domain/pricing"
+)
+
+var (
+	ErrNotFound       = errors.New("price rule not found")
+	ErrTargetNotFound = errors.New("target not found")
+)
+
+// targetTables maps rule targets to the table holding them
+var targetTables = map[string]string{
+	pricing.TargetCategory:    "categories",
+	pricing.TargetProductType: "product_types",
+	pricing.TargetProduct:     "products",
+	pricing.TargetVariant:     "product_variants",
+}
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// List returns rules newest first; with liveOnly only those in effect now
+func (r *Repo) List(liveOnly bool, limit, offset int) ([]pricing.PriceRule, int64, error) {
+	q := r.db.Model(&pricing.PriceRule{})
+	if liveOnly {
+		q = q.Where("is_active AND starts_at <= now() AND (ends_at IS NULL OR ends_at > now())")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []pricing.PriceRule
+	err := q.Order("starts_at DESC, id DESC").Limit(limit).Offset(offset).Find(&out).Error
+	return out, total, err
+}
+
+func (r *Repo) ByID(id int64) (pricing.PriceRule, error) {
+	var pr pricing.PriceRule
+	err := r.db.First(&pr, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return pr, ErrNotFound
+	}
+	return pr, err
+}
+
+func (r *Repo) Create(pr *pricing.PriceRule) error {
+	if err := r.checkTarget(pr.TargetType, pr.TargetID); err != nil {
+		return err
+	}
+	return r.db.Create(pr).Error
+}
+
+func (r *Repo) Update(pr *pricing.PriceRule) error {
+	if err := r.checkTarget(pr.TargetType, pr.TargetID); err != nil {
+		return err
+	}
+	res := r.db.Model(&pricing.PriceRule{}).Where("id = ?", pr.ID).Select(
+		"name", "target_type", "target_id", "percent_off", "starts_at", "ends_at", "is_active",
+	).Updates(pr)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repo) Delete(id int64) error {
+	res := r.db.Delete(&pricing.PriceRule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repo) checkTarget(targetType string, id int64) error {
+	table, ok := targetTables[targetType]
+	if !ok {
+		return ErrTargetNotFound
+	}
+	var n int64
+	if err := r.db.Table(table).Where("id = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
@@ -1,20 +1,29 @@
 package products
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	"ecommerce/internal/auth"
+	taxDomain "ecommerce/internal/domain/tax"
+	"ecommerce/internal/tax"
 )
 
+// RestockNotifier is told when a sold-out variant gets stock again
+type RestockNotifier interface {
+	VariantRestocked(variantID int64)
+}
+
 type Handler struct {
-	repo *Repo
+	repo    *Repo
+	restock RestockNotifier
 }
 
-func NewHandler(repo *Repo) *Handler {
-	return &Handler{repo: repo}
+func NewHandler(repo *Repo, restock RestockNotifier) *Handler {
+	return &Handler{repo: repo, restock: restock}
 }
 
 // Public: list products (optional category=slug)
@@ -48,6 +57,7 @@ type CreateProductReq struct {
 	TypeName    string `json:"type_name" binding:"required"`
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	TaxClass    string `json:"tax_class"` // default "standard"
 
 	Variants []CreateVariantReq `json:"variants" binding:"required"`
 }
@@ -58,6 +68,7 @@ type CreateVariantReq struct {
 	Price           float64 `json:"price" binding:"required"`
 	DiscountPercent int     `json:"discount_percent"`
 	StockQty        int     `json:"stock_qty" binding:"required"`
+	WeightGrams     int     `json:"weight_grams"`
 }
 
 // Admin: create product + variants
@@ -68,6 +79,14 @@ func (h *Handler) AdminCreate(c *gin.Context) {
 		return
 	}
 
+	if req.TaxClass == "" {
+		req.TaxClass = taxDomain.ClassStandard
+	}
+	if !tax.ValidClass(req.TaxClass) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tax_class must be lowercase letters, digits or _"})
+		return
+	}
+
 	userIDAny, _ := c.Get(auth.CtxUserIDKey)
 	userID := userIDAny.(int64)
 
@@ -79,6 +98,7 @@ func (h *Handler) AdminCreate(c *gin.Context) {
 			Price:           v.Price,
 			DiscountPercent: v.DiscountPercent,
 			StockQty:        v.StockQty,
+			WeightGrams:     v.WeightGrams,
 		})
 	}
 
@@ -87,6 +107,7 @@ func (h *Handler) AdminCreate(c *gin.Context) {
 		TypeName:    req.TypeName,
 		Name:        req.Name,
 		Description: req.Description,
+		TaxClass:    req.TaxClass,
 		CreatedBy:   userID,
 		Variants:    vars,
 	})
@@ -97,3 +118,59 @@ func (h *Handler) AdminCreate(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, p)
 }
+
+type UpdateWeightReq struct {
+	WeightGrams *int `json:"weight_grams" binding:"required"`
+}
+
+// Admin: set a variant's shipping weight
+func (h *Handler) AdminUpdateWeight(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req UpdateWeightReq
+	if err := c.ShouldBindJSON(&req); err != nil || *req.WeightGrams < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weight_grams must be >= 0"})
+		return
+	}
+	if err := h.repo.SetVariantWeight(id, *req.WeightGrams); err != nil {
+		if errors.Is(err, ErrVariantNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "variant not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update weight"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"variant_id": id, "weight_grams": *req.WeightGrams})
+}
+
+type UpdateStockReq struct {
+	StockQty *int `json:"stock_qty"` // absolute value
+	Delta    *int `json:"delta"`     // or relative adjustment
+}
+
+// Admin: set or adjust a variant's stock
+func (h *Handler) AdminUpdateStock(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req UpdateStockReq
+	if err := c.ShouldBindJSON(&req); err != nil || (req.StockQty == nil) == (req.Delta == nil) ||
+		(req.StockQty != nil && *req.StockQty < 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "send either stock_qty (>= 0) or delta"})
+		return
+	}
+
+	before, after, err := h.repo.SetVariantStock(id, req.StockQty, req.Delta)
+	if errors.Is(err, ErrVariantNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "variant not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update stock"})
+		return
+	}
+
+	if before == 0 && after > 0 && h.restock != nil {
+		h.restock.VariantRestocked(id)
+	}
+	c.JSON(http.StatusOK, gin.H{"variant_id": id, "stock_qty": after, "previous_stock_qty": before})
+}
@@ -1,8 +1,8 @@
 package products
 
 import (
+	"errors"
 	"fmt"
-	"math"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -36,6 +36,7 @@ type CreateProductInput struct {
 	TypeName    string
 	Name        string
 	Description string
+	TaxClass    string
 	CreatedBy   int64
 	Variants    []CreateVariantInput
 }
@@ -46,6 +47,7 @@ type CreateVariantInput struct {
 	Price           float64
 	DiscountPercent int
 	StockQty        int
+	WeightGrams     int
 }
 
 func (r *Repo) CreateProductWithVariants(in CreateProductInput) (product.Product, error) {
@@ -80,6 +82,7 @@ func (r *Repo) CreateProductWithVariants(in CreateProductInput) (product.Product
 		Name:        in.Name,
 		Description: in.Description,
 		IsActive:    true,
+		TaxClass:    in.TaxClass,
 		CreatedBy:   &in.CreatedBy,
 	}
 	if err := tx.Create(&p).Error; err != nil {
@@ -96,6 +99,7 @@ func (r *Repo) CreateProductWithVariants(in CreateProductInput) (product.Product
 			Price:           v.Price,
 			DiscountPercent: v.DiscountPercent,
 			StockQty:        v.StockQty,
+			WeightGrams:     v.WeightGrams,
 		}
 		if err := tx.Create(&variant).Error; err != nil {
 			tx.Rollback()
@@ -113,9 +117,17 @@ func (r *Repo) ListPublic(categorySlug *string) ([]product.Product, error) {
 	query := r.db.Table("products p").
 		Select(`p.id, p.category_id, p.type_id, p.name, COALESCE(p.description,'') as description,
 		        p.is_active, p.created_at, p.updated_at,
-		        c.name as category, pt.name as type_name`).
+		        c.name as category, pt.name as type_name,
+		        pp.price_from, COALESCE(pp.on_sale, false) as on_sale`).
 		Joins("JOIN categories c ON c.id = p.category_id").
 		Joins("JOIN product_types pt ON pt.id = p.type_id").
+		Joins(`LEFT JOIN (
+		        SELECT v.product_id, MIN(vp.final_price) AS price_from,
+		               BOOL_OR(vp.price_rule_id IS NOT NULL) AS on_sale
+		        FROM product_variants v
+		        JOIN variant_prices vp ON vp.variant_id = v.id
+		        GROUP BY v.product_id
+		      ) pp ON pp.product_id = p.id`).
 		Where("p.is_active = ? AND c.is_active = ?", true, true)
 
 	if categorySlug != nil && *categorySlug != "" {
@@ -137,6 +149,7 @@ func (r *Repo) ListPublic(categorySlug *string) ([]product.Product, error) {
 			&p.ID, &p.CategoryID, &p.TypeID, &p.Name, &p.Description,
 			&p.IsActive, &p.CreatedAt, &p.UpdatedAt,
 			&p.Category, &p.TypeName,
+			&p.PriceFrom, &p.OnSale,
 		); err != nil {
 			return nil, err
 		}
@@ -150,32 +163,104 @@ func (r *Repo) GetProductPublic(id int64) (product.Product, error) {
 
 	err := r.db.Table("products p").
 		Select(`p.id, p.category_id, p.type_id, p.name, COALESCE(p.description,'') as description,
-		        p.is_active, p.created_at, p.updated_at,
+		        p.is_active, p.tax_class, p.created_at, p.updated_at,
 		        c.name as category, pt.name as type_name`).
 		Joins("JOIN categories c ON c.id = p.category_id").
 		Joins("JOIN product_types pt ON pt.id = p.type_id").
 		Where("p.id = ? AND p.is_active = ? AND c.is_active = ?", id, true, true).
 		Row().Scan(
 		&p.ID, &p.CategoryID, &p.TypeID, &p.Name, &p.Description,
-		&p.IsActive, &p.CreatedAt, &p.UpdatedAt,
+		&p.IsActive, &p.TaxClass, &p.CreatedAt, &p.UpdatedAt,
 		&p.Category, &p.TypeName,
 	)
 	if err != nil {
 		return product.Product{}, err
 	}
 
-	// Load variants
-	var variants []product.Variant
-	err = r.db.Where("product_id = ?", p.ID).Order("id ASC").Find(&variants).Error
+	// Load variants with their current price (scheduled sales included)
+	variants, err := r.variantsWithPrices(p.ID)
 	if err != nil {
 		return product.Product{}, err
 	}
-
-	// Compute final price for each variant
-	for i := range variants {
-		variants[i].FinalPrice = math.Round(variants[i].Price*float64(100-variants[i].DiscountPercent)) / 100.0
-	}
 	p.Variants = variants
+	for _, v := range variants {
+		if p.PriceFrom == nil || v.FinalPrice < *p.PriceFrom {
+			price := v.FinalPrice
+			p.PriceFrom = &price
+		}
+		p.OnSale = p.OnSale || v.OnSale
+	}
 
 	return p, nil
 }
+
+// variantsWithPrices loads a product's variants priced through the
+// variant_prices view; DiscountPercent is the effective discount.
+func (r *Repo) variantsWithPrices(productID int64) ([]product.Variant, error) {
+	rows, err := r.db.Table("product_variants v").
+		Select(`v.id, v.product_id, v.size, v.color, v.price,
+		        vp.discount_percent, vp.final_price, vp.sale_ends_at,
+		        vp.price_rule_id IS NOT NULL as on_sale,
+		        v.stock_qty, v.weight_grams, v.created_at, v.updated_at`).
+		Joins("JOIN variant_prices vp ON vp.variant_id = v.id").
+		Where("v.product_id = ?", productID).
+		Order("v.id ASC").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []product.Variant{}
+	for rows.Next() {
+		var v product.Variant
+		if err := rows.Scan(
+			&v.ID, &v.ProductID, &v.Size, &v.Color, &v.Price,
+			&v.DiscountPercent, &v.FinalPrice, &v.SaleEndsAt,
+			&v.OnSale,
+			&v.StockQty, &v.WeightGrams, &v.CreatedAt, &v.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+
+var ErrVariantNotFound = errors.New("variant not found")
+
+func (r *Repo) SetVariantWeight(variantID int64, grams int) error {
+	res := r.db.Model(&product.Variant{}).Where("id = ?", variantID).Update("weight_grams", grams)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrVariantNotFound
+	}
+	return nil
+}
+
+// SetVariantStock sets stock to qty, or adjusts it by delta when qty is nil,
+// and returns the stock before and after. Stock never goes below zero.
+func (r *Repo) SetVariantStock(variantID int64, qty, delta *int) (before, after int, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var v product.Variant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&v, variantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrVariantNotFound
+			}
+			return err
+		}
+		before = v.StockQty
+		if qty != nil {
+			after = *qty
+		} else {
+			after = before + *delta
+		}
+		if after < 0 {
+			after = 0
+		}
+		return tx.Model(&v).Update("stock_qty", after).Error
+	})
+	return before, after, err
+}
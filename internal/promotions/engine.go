@@ -0,0 +1,173 @@
+package promotions
+
+import (
+	"math"
+	"sort"
+
+	"ecommerce/internal/domain/promotion"
+)
+
+// The engine is pure: callers load rules and cart lines, Evaluate does the
+// math. Rules of evaluation:
+//   - rules run by Priority (highest first), then by ID
+//   - every unit in the cart can be discounted by at most one rule, so rules
+//     evaluated later only see the units earlier rules left untouched
+//   - bundles consume units from the lowest line ID first
+//   - discounts are a percentage of the line's unit price and rounded to
+//     cents per line and rule
+
+// Rule is one live promotion
+type Rule struct {
+	ID         int64
+	Name       string
+	Kind       string
+	PercentOff int
+	Priority   int
+
+	// quantity tier
+	TargetType string
+	TargetID   int64
+	MinQty     int
+
+	// bundle
+	Components []Component
+}
+
+type Component struct {
+	ProductID int64
+	Qty       int
+}
+
+// Line is one purchasable cart line
+type Line struct {
+	ID         int64
+	VariantID  int64
+	ProductID  int64
+	CategoryID int64
+	Qty        int
+	UnitPrice  float64
+}
+
+// Applied is one rule's effect on one line
+type Applied struct {
+	PromotionID int64   `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Units       int     `json:"units"`
+	Discount    float64 `json:"discount"`
+}
+
+type Result struct {
+	Lines    map[int64][]Applied // by Line.ID
+	Discount float64
+}
+
+// Evaluate applies rules to lines. Inputs are not modified.
+func Evaluate(rules []Rule, lines []Line) Result {
+	rs := append([]Rule(nil), rules...)
+	sort.SliceStable(rs, func(i, j int) bool {
+		if rs[i].Priority != rs[j].Priority {
+			return rs[i].Priority > rs[j].Priority
+		}
+		return rs[i].ID < rs[j].ID
+	})
+	ls := append([]Line(nil), lines...)
+	sort.SliceStable(ls, func(i, j int) bool { return ls[i].ID < ls[j].ID })
+
+	free := make([]int, len(ls)) // units not yet discounted
+	for i, l := range ls {
+		free[i] = l.Qty
+	}
+
+	res := Result{Lines: map[int64][]Applied{}}
+	apply := func(r Rule, i, units int) {
+		if units <= 0 {
+			return
+		}
+		free[i] -= units
+		d := round2(ls[i].UnitPrice * float64(units) * float64(r.PercentOff) / 100)
+		res.Lines[ls[i].ID] = append(res.Lines[ls[i].ID], Applied{
+			PromotionID: r.ID, Name: r.Name, Units: units, Discount: d,
+		})
+		res.Discount += d
+	}
+
+	for _, r := range rs {
+		switch r.Kind {
+		case promotion.KindQuantityTier:
+			total := 0
+			for i, l := range ls {
+				if r.matches(l) {
+					total += free[i]
+				}
+			}
+			if r.MinQty <= 0 || total < r.MinQty {
+				continue
+			}
+			for i, l := range ls {
+				if r.matches(l) {
+					apply(r, i, free[i])
+				}
+			}
+
+		case promotion.KindBundle:
+			if len(r.Components) == 0 {
+				continue
+			}
+			bundles := -1
+			for _, comp := range r.Components {
+				if comp.Qty <= 0 {
+					bundles = 0
+					break
+				}
+				have := 0
+				for i, l := range ls {
+					if l.ProductID == comp.ProductID {
+						have += free[i]
+					}
+				}
+				if n := have / comp.Qty; bundles < 0 || n < bundles {
+					bundles = n
+				}
+			}
+			if bundles <= 0 {
+				continue
+			}
+			for _, comp := range r.Components {
+				need := bundles * comp.Qty
+				for i, l := range ls {
+					if need == 0 {
+						break
+					}
+					if l.ProductID != comp.ProductID {
+						continue
+					}
+					take := free[i]
+					if take > need {
+						take = need
+					}
+					apply(r, i, take)
+					need -= take
+				}
+			}
+		}
+	}
+
+	res.Discount = round2(res.Discount)
+	return res
+}
+
+func (r Rule) matches(l Line) bool {
+	switch r.TargetType {
+	case promotion.TargetCategory:
+		return l.CategoryID == r.TargetID
+	case promotion.TargetProduct:
+		return l.ProductID == r.TargetID
+	case promotion.TargetVariant:
+		return l.VariantID == r.TargetID
+	}
+	return false
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,188 @@
+package promotions
+
+import (
+	"reflect"
+	"testing"
+
+	"ecommerce/internal/domain/promotion"
+)
+
+func tier(id int64, priority, percent int, target string, targetID int64, minQty int) Rule {
+	return Rule{
+		ID: id, Name: "tier", Kind: promotion.KindQuantityTier, PercentOff: percent, Priority: priority,
+		TargetType: target, TargetID: targetID, MinQty: minQty,
+	}
+}
+
+func bundle(id int64, priority, percent int, comps ...Component) Rule {
+	return Rule{ID: id, Name: "bundle", Kind: promotion.KindBundle, PercentOff: percent, Priority: priority, Components: comps}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []Rule
+		lines    []Line
+		want     map[int64][]Applied
+		discount float64
+	}{
+		{
+			name:  "tier below min qty does nothing",
+			rules: []Rule{tier(1, 0, 10, promotion.TargetProduct, 7, 3)},
+			lines: []Line{{ID: 1, ProductID: 7, Qty: 2, UnitPrice: 10}},
+			want:  map[int64][]Applied{},
+		},
+		{
+			name:  "tier at min qty counts units across lines",
+			rules: []Rule{tier(1, 0, 10, promotion.TargetCategory, 3, 3)},
+			lines: []Line{
+				{ID: 1, ProductID: 7, CategoryID: 3, Qty: 2, UnitPrice: 10},
+				{ID: 2, ProductID: 8, CategoryID: 3, Qty: 1, UnitPrice: 20},
+				{ID: 3, ProductID: 9, CategoryID: 4, Qty: 5, UnitPrice: 20},
+			},
+			want: map[int64][]Applied{
+				1: {{PromotionID: 1, Name: "tier", Units: 2, Discount: 2}},
+				2: {{PromotionID: 1, Name: "tier", Units: 1, Discount: 2}},
+			},
+			discount: 4,
+		},
+		{
+			name: "higher priority runs first and takes the units",
+			rules: []Rule{
+				tier(1, 0, 10, promotion.TargetProduct, 7, 1),
+				tier(2, 5, 20, promotion.TargetVariant, 70, 1),
+			},
+			lines: []Line{{ID: 1, VariantID: 70, ProductID: 7, Qty: 2, UnitPrice: 10}},
+			want: map[int64][]Applied{
+				1: {{PromotionID: 2, Name: "tier", Units: 2, Discount: 4}},
+			},
+			discount: 4,
+		},
+		{
+			name: "equal priority runs by id",
+			rules: []Rule{
+				tier(9, 1, 50, promotion.TargetProduct, 7, 1),
+				tier(3, 1, 10, promotion.TargetProduct, 7, 1),
+			},
+			lines: []Line{{ID: 1, ProductID: 7, Qty: 1, UnitPrice: 10}},
+			want: map[int64][]Applied{
+				1: {{PromotionID: 3, Name: "tier", Units: 1, Discount: 1}},
+			},
+			discount: 1,
+		},
+		{
+			name: "bundle leaves only spare units to a later tier",
+			rules: []Rule{
+				bundle(1, 10, 20, Component{ProductID: 7, Qty: 1}, Component{ProductID: 8, Qty: 1}),
+				tier(2, 0, 10, promotion.TargetProduct, 7, 2),
+			},
+			lines: []Line{
+				{ID: 1, ProductID: 7, Qty: 3, UnitPrice: 10},
+				{ID: 2, ProductID: 8, Qty: 1, UnitPrice: 5},
+			},
+			want: map[int64][]Applied{
+				1: {
+					{PromotionID: 1, Name: "bundle", Units: 1, Discount: 2},
+					{PromotionID: 2, Name: "tier", Units: 2, Discount: 2},
+				},
+				2: {{PromotionID: 1, Name: "bundle", Units: 1, Discount: 1}},
+			},
+			discount: 5,
+		},
+		{
+			name: "later tier misses min qty once a bundle used the units",
+			rules: []Rule{
+				bundle(1, 10, 20, Component{ProductID: 7, Qty: 2}),
+				tier(2, 0, 10, promotion.TargetProduct, 7, 2),
+			},
+			lines: []Line{{ID: 1, ProductID: 7, Qty: 3, UnitPrice: 10}},
+			want: map[int64][]Applied{
+				1: {{PromotionID: 1, Name: "bundle", Units: 2, Discount: 4}},
+			},
+			discount: 4,
+		},
+		{
+			name:  "bundle count with component qty > 1",
+			rules: []Rule{bundle(1, 0, 10, Component{ProductID: 7, Qty: 2}, Component{ProductID: 8, Qty: 1})},
+			lines: []Line{
+				{ID: 1, ProductID: 7, Qty: 5, UnitPrice: 10},
+				{ID: 2, ProductID: 8, Qty: 4, UnitPrice: 30},
+			},
+			want: map[int64][]Applied{
+				1: {{PromotionID: 1, Name: "bundle", Units: 4, Discount: 4}},
+				2: {{PromotionID: 1, Name: "bundle", Units: 2, Discount: 6}},
+			},
+			discount: 10,
+		},
+		{
+			name:  "bundle consumes from the lowest line id first",
+			rules: []Rule{bundle(1, 0, 50, Component{ProductID: 7, Qty: 3})},
+			lines: []Line{
+				{ID: 5, VariantID: 71, ProductID: 7, Qty: 2, UnitPrice: 20},
+				{ID: 2, VariantID: 70, ProductID: 7, Qty: 2, UnitPrice: 10},
+			},
+			want: map[int64][]Applied{
+				2: {{PromotionID: 1, Name: "bundle", Units: 2, Discount: 10}},
+				5: {{PromotionID: 1, Name: "bundle", Units: 1, Discount: 10}},
+			},
+			discount: 20,
+		},
+		{
+			name:  "incomplete bundle does nothing",
+			rules: []Rule{bundle(1, 0, 10, Component{ProductID: 7, Qty: 1}, Component{ProductID: 8, Qty: 1})},
+			lines: []Line{{ID: 1, ProductID: 7, Qty: 4, UnitPrice: 10}},
+			want:  map[int64][]Applied{},
+		},
+		{
+			name:  "discount is rounded to cents per line",
+			rules: []Rule{tier(1, 0, 15, promotion.TargetCategory, 3, 1)},
+			lines: []Line{
+				{ID: 1, ProductID: 7, CategoryID: 3, Qty: 1, UnitPrice: 0.33},
+				{ID: 2, ProductID: 8, CategoryID: 3, Qty: 1, UnitPrice: 0.33},
+				{ID: 3, ProductID: 9, CategoryID: 3, Qty: 3, UnitPrice: 0.33},
+			},
+			want: map[int64][]Applied{
+				1: {{PromotionID: 1, Name: "tier", Units: 1, Discount: 0.05}},
+				2: {{PromotionID: 1, Name: "tier", Units: 1, Discount: 0.05}},
+				3: {{PromotionID: 1, Name: "tier", Units: 3, Discount: 0.15}},
+			},
+			discount: 0.25,
+		},
+		{
+			name:  "per-line rounding is summed, not re-rounded from the raw total",
+			rules: []Rule{tier(1, 0, 10, promotion.TargetProduct, 7, 1)},
+			lines: []Line{
+				{ID: 1, VariantID: 70, ProductID: 7, Qty: 1, UnitPrice: 0.04},
+				{ID: 2, VariantID: 71, ProductID: 7, Qty: 1, UnitPrice: 0.04},
+				{ID: 3, VariantID: 72, ProductID: 7, Qty: 1, UnitPrice: 0.04},
+			},
+			want: map[int64][]Applied{
+				1: {{PromotionID: 1, Name: "tier", Units: 1, Discount: 0}},
+				2: {{PromotionID: 1, Name: "tier", Units: 1, Discount: 0}},
+				3: {{PromotionID: 1, Name: "tier", Units: 1, Discount: 0}},
+			},
+			discount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Evaluate(tt.rules, tt.lines)
+			if !reflect.DeepEqual(res.Lines, tt.want) {
+				t.Errorf("lines = %+v, want %+v", res.Lines, tt.want)
+			}
+			if res.Discount != tt.discount {
+				t.Errorf("discount = %v, want %v", res.Discount, tt.discount)
+			}
+		})
+	}
+}
+
+func TestEvaluateDoesNotModifyInputs(t *testing.T) {
+	rules := []Rule{tier(1, 0, 10, promotion.TargetProduct, 7, 1), tier(2, 5, 10, promotion.TargetProduct, 7, 1)}
+	lines := []Line{{ID: 2, ProductID: 7, Qty: 1, UnitPrice: 10}, {ID: 1, ProductID: 7, Qty: 1, UnitPrice: 10}}
+	Evaluate(rules, lines)
+	if rules[0].ID != 1 || lines[0].ID != 2 || lines[0].Qty != 1 {
+		t.Errorf("inputs were modified: %+v %+v", rules, lines)
+	}
+}
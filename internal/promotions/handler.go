@@ -0,0 +1,172 @@
+package promotions
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/audit"
+	"ecommerce/internal/domain/promotion"
+)
+
+type Handler struct {
+	repo *Repo
+}
+
+func NewHandler(repo *Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+type BundleItemReq struct {
+	ProductID int64 `json:"product_id" binding:"required"`
+	Qty       int   `json:"qty"`
+}
+
+type PromotionReq struct {
+	Name        string          `json:"name" binding:"required"`
+	Kind        string          `json:"kind" binding:"required"`
+	PercentOff  int             `json:"percent_off" binding:"required"`
+	TargetType  *string         `json:"target_type"`
+	TargetID    *int64          `json:"target_id"`
+	MinQty      *int            `json:"min_qty"`
+	Priority    int             `json:"priority"`
+	StartsAt    *time.Time      `json:"starts_at"`
+	EndsAt      *time.Time      `json:"ends_at"`
+	IsActive    *bool           `json:"is_active"`
+	BundleItems []BundleItemReq `json:"bundle_items"`
+}
+
+func (req PromotionReq) toPromotion() (promotion.Promotion, string) {
+	p := promotion.Promotion{
+		Name:       strings.TrimSpace(req.Name),
+		Kind:       req.Kind,
+		PercentOff: req.PercentOff,
+		Priority:   req.Priority,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+	}
+	if p.Name == "" {
+		return p, "name is required"
+	}
+	if p.PercentOff <= 0 || p.PercentOff > 100 {
+		return p, "percent_off must be between 1 and 100"
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return p, "ends_at must be after starts_at"
+	}
+
+	switch p.Kind {
+	case promotion.KindQuantityTier:
+		if req.TargetType == nil || req.TargetID == nil || req.MinQty == nil || *req.MinQty <= 0 {
+			return p, "quantity_tier needs target_type, target_id and min_qty"
+		}
+		switch *req.TargetType {
+		case promotion.TargetCategory, promotion.TargetProduct, promotion.TargetVariant:
+		default:
+			return p, "target_type must be category, product or variant"
+		}
+		p.TargetType, p.TargetID, p.MinQty = req.TargetType, req.TargetID, req.MinQty
+	case promotion.KindBundle:
+		if len(req.BundleItems) < 2 {
+			return p, "a bundle needs at least two products"
+		}
+		seen := map[int64]bool{}
+		for _, it := range req.BundleItems {
+			if it.Qty == 0 {
+				it.Qty = 1
+			}
+			if it.Qty < 0 || seen[it.ProductID] {
+				return p, "invalid bundle_items"
+			}
+			seen[it.ProductID] = true
+			p.BundleItems = append(p.BundleItems, promotion.BundleItem{ProductID: it.ProductID, Qty: it.Qty})
+		}
+	default:
+		return p, "kind must be quantity_tier or bundle"
+	}
+	return p, ""
+}
+
+func (h *Handler) AdminList(c *gin.Context) {
+	page, pageSize := audit.Pagination(c)
+	items, total, err := h.repo.List(pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list promotions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total, "page": page, "page_size": pageSize})
+}
+
+func (h *Handler) AdminGet(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	p, err := h.repo.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *Handler) AdminCreate(c *gin.Context) {
+	var req PromotionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	p, msg := req.toPromotion()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.repo.Create(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to create promotion"})
+		return
+	}
+	p, _ = h.repo.ByID(p.ID)
+	c.JSON(http.StatusCreated, p)
+}
+
+// AdminUpdate replaces the promotion definition (PUT semantics)
+func (h *Handler) AdminUpdate(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req PromotionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	p, msg := req.toPromotion()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	p.ID = id
+	if err := h.repo.Update(&p); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to update promotion"})
+		return
+	}
+	p, _ = h.repo.ByID(id)
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *Handler) AdminDelete(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.repo.Delete(id); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete promotion"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
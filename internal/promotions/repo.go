@@ -0,0 +1,122 @@
+package promotions
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"ecommerce/internal/domain/promotion"
+)
+
+var ErrNotFound = errors.New("promotion not found")
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) List(limit, offset int) ([]promotion.Promotion, int64, error) {
+	var total int64
+	if err := r.db.Model(&promotion.Promotion{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []promotion.Promotion
+	err := r.db.Preload("BundleItems").
+		Order("priority DESC, id ASC").Limit(limit).Offset(offset).
+		Find(&out).Error
+	return out, total, err
+}
+
+func (r *Repo) ByID(id int64) (promotion.Promotion, error) {
+	var p promotion.Promotion
+	err := r.db.Preload("BundleItems").First(&p, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return p, ErrNotFound
+	}
+	return p, err
+}
+
+func (r *Repo) Create(p *promotion.Promotion) error {
+	items := p.BundleItems
+	p.BundleItems = nil
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(p).Error; err != nil {
+			return err
+		}
+		return setBundleItems(tx, p.ID, items)
+	})
+}
+
+func (r *Repo) Update(p *promotion.Promotion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&promotion.Promotion{}).Where("id = ?", p.ID).Select(
+			"name", "kind", "percent_off", "target_type", "target_id", "min_qty",
+			"priority", "starts_at", "ends_at", "is_active",
+		).Updates(p)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return setBundleItems(tx, p.ID, p.BundleItems)
+	})
+}
+
+func (r *Repo) Delete(id int64) error {
+	res := r.db.Delete(&promotion.Promotion{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func setBundleItems(tx *gorm.DB, promotionID int64, items []promotion.BundleItem) error {
+	if err := tx.Where("promotion_id = ?", promotionID).Delete(&promotion.BundleItem{}).Error; err != nil {
+		return err
+	}
+	for _, it := range items {
+		it.PromotionID = promotionID
+		if err := tx.Create(&it).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Live returns the promotions in effect at now as engine rules
+func (r *Repo) Live(now time.Time) ([]Rule, error) {
+	var ps []promotion.Promotion
+	err := r.db.Preload("BundleItems").
+		Where("is_active AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", now, now).
+		Find(&ps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(ps))
+	for _, p := range ps {
+		rule := Rule{ID: p.ID, Name: p.Name, Kind: p.Kind, PercentOff: p.PercentOff, Priority: p.Priority}
+		if p.TargetType != nil {
+			rule.TargetType = *p.TargetType
+		}
+		if p.TargetID != nil {
+			rule.TargetID = *p.TargetID
+		}
+		if p.MinQty != nil {
+			rule.MinQty = *p.MinQty
+		}
+		for _, it := range p.BundleItems {
+			rule.Components = append(rule.Components, Component{ProductID: it.ProductID, Qty: it.Qty})
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limiter allows a number of requests per key in a fixed time window. State
+// is kept in memory, so with several API instances each enforces its own
+// limit; that is enough to stop guessing and mail-bombing from one client.
+type Limiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	count int
+	reset time.Time
+}
+
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{limit: limit, window: window, now: time.Now, buckets: map[string]*bucket{}}
+}
+
+// Allow counts a request for key. When the limit is reached it returns
+// false and when the window resets.
+func (l *Limiter) Allow(key string) (bool, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok || !now.Before(b.reset) {
+		b = &bucket{reset: now.Add(l.window)}
+		l.buckets[key] = b
+	}
+	if b.count >= l.limit {
+		return false, b.reset
+	}
+	b.count++
+	return true, b.reset
+}
+
+// sweep drops expired buckets at most once per window
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.window {
+		return
+	}
+	for k, b := range l.buckets {
+		if !now.Before(b.reset) {
+			delete(l.buckets, k)
+		}
+	}
+	l.swept = now
+}
+
+// PerIP limits requests per client IP (see gin's SetTrustedProxies)
+func PerIP(limit int, window time.Duration) gin.HandlerFunc {
+	l := New(limit, window)
+	return func(c *gin.Context) {
+		ok, reset := l.Allow(c.ClientIP())
+		if !ok {
+			retry := int(math.Ceil(time.Until(reset).Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retry))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests, try again later"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := New(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("1.2.3.4"); !ok {
+			t.Fatalf("request %d was limited", i+1)
+		}
+	}
+	if ok, reset := l.Allow("1.2.3.4"); ok || !reset.Equal(now.Add(time.Minute)) {
+		t.Fatalf("third request: ok=%v reset=%v", ok, reset)
+	}
+	if ok, _ := l.Allow("5.6.7.8"); !ok {
+		t.Fatal("other key was limited")
+	}
+
+	now = now.Add(time.Minute)
+	if ok, _ := l.Allow("1.2.3.4"); !ok {
+		t.Fatal("limit did not reset after the window")
+	}
+	if _, found := l.buckets["5.6.7.8"]; found {
+		t.Error("expired bucket was not swept")
+	}
+}
@@ -0,0 +1,141 @@
+package restock
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/auth"
+	"ecommerce/internal/mail"
+	"ecommerce/internal/util"
+)
+
+// confirmTTL is how long a guest has to click the confirmation link
+const confirmTTL = 24 * time.Hour
+
+type Config struct {
+	SigningSecret string // signs the guest confirmation link
+	BaseURL       string // public API base, e.g. http://localhost:8080
+}
+
+type Handler struct {
+	repo   *Repo
+	mailer mail.Mailer
+	cfg    Config
+}
+
+func NewHandler(repo *Repo, mailer mail.Mailer, cfg Config) *Handler {
+	return &Handler{repo: repo, mailer: mailer, cfg: cfg}
+}
+
+type NotifyMeReq struct {
+	Email string `json:"email"` // required for guests
+}
+
+// NotifyMe subscribes the caller to a back-in-stock email for a sold-out
+// variant. Guests get a confirmation email first and are only notified
+// once they click it, so nobody can sign up someone else's address.
+func (h *Handler) NotifyMe(c *gin.Context) {
+	variantID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req NotifyMeReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+	}
+
+	v, err := h.repo.Variant(variantID)
+	if errors.Is(err, ErrVariantNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "variant not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load variant"})
+		return
+	}
+	if v.StockQty > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "variant is in stock"})
+		return
+	}
+
+	var userID *int64
+	email := strings.TrimSpace(strings.ToLower(req.Email))
+	if uid := c.GetInt64(auth.CtxUserIDKey); uid != 0 {
+		userID = &uid
+		if email, err = h.repo.EmailOf(uid); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load user"})
+			return
+		}
+	} else if !strings.Contains(email, "@") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email required"})
+		return
+	}
+
+	sub, created, err := h.repo.Subscribe(variantID, userID, email, userID != nil, confirmTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to subscribe"})
+		return
+	}
+	if userID != nil {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+		return
+	}
+
+	if created {
+		expiresAt := time.Now().Add(confirmTTL)
+		link := fmt.Sprintf("%s/api/stock-notifications/%d/confirm?expires=%d&sig=%s",
+			h.cfg.BaseURL, sub.ID, expiresAt.Unix(), util.SignExpiring(h.cfg.SigningSecret, subscriptionResource(sub.ID), expiresAt))
+		body := fmt.Sprintf("Someone asked us to email this address when %s (size %s, %s) is back in stock.\n\n"+
+			"Confirm here: %s\n\nIf it wasn't you, ignore this email and you won't hear from us.",
+			v.Product, v.Size, v.Color, link)
+		if err := h.mailer.Send(email, "Confirm your back-in-stock alert", body); err != nil {
+			log.Printf("restock subscription %d: confirmation email: %v", sub.ID, err)
+		}
+	}
+	// same answer whether or not the address was already subscribed
+	c.JSON(http.StatusAccepted, gin.H{
+		"ok":      true,
+		"message": "Check your email to confirm the alert.",
+	})
+}
+
+// Confirm activates a guest subscription from the emailed link
+func (h *Handler) Confirm(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if !util.VerifyExpiring(h.cfg.SigningSecret, subscriptionResource(id), expires, c.Query("sig")) {
+		c.JSON(http.StatusGone, gin.H{"error": "link invalid or expired"})
+		return
+	}
+
+	err = h.repo.Confirm(id)
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to confirm"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func subscriptionResource(id int64) string {
+	return "stock-notification:" + strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,77 @@
+package restock
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"ecommerce/internal/mail"
+)
+
+const (
+	batchSize  = 100
+	batchPause = time.Second // keep well under SMTP provider rate limits
+)
+
+// Notifier emails back-in-stock subscribers
+type Notifier struct {
+	repo    *Repo
+	mailer  mail.Mailer
+	baseURL string
+}
+
+func NewNotifier(repo *Repo, mailer mail.Mailer, baseURL string) *Notifier {
+	return &Notifier{repo: repo, mailer: mailer, baseURL: baseURL}
+}
+
+// VariantRestocked is called after an admin stock update takes a variant
+// from 0 to positive stock. Sending happens in the background.
+func (n *Notifier) VariantRestocked(variantID int64) {
+	go n.notify(variantID)
+}
+
+// notify walks the subscriptions in batches. Sent subscriptions are deleted;
+// failed ones stay for the next restock.
+func (n *Notifier) notify(variantID int64) {
+	v, err := n.repo.Variant(variantID)
+	if err != nil {
+		log.Printf("restock %d: %v", variantID, err)
+		return
+	}
+	subject := fmt.Sprintf("%s is back in stock", v.Product)
+	body := fmt.Sprintf(
+		"Good news! %s (size %s, %s) is available again.\n\n%s/products/%d\n\nStock is limited, so it may sell out again soon.",
+		v.Product, v.Size, v.Color, n.baseURL, v.ProductID,
+	)
+
+	var afterID int64
+	for {
+		subs, err := n.repo.Batch(variantID, afterID, batchSize)
+		if err != nil {
+			log.Printf("restock %d: load subscriptions: %v", variantID, err)
+			return
+		}
+		if len(subs) == 0 {
+			return
+		}
+
+		sent := make([]int64, 0, len(subs))
+		for _, s := range subs {
+			if err := n.mailer.Send(s.Email, subject, body); err != nil {
+				log.Printf("restock %d: email to subscription %d failed: %v", variantID, s.ID, err)
+				continue
+			}
+			sent = append(sent, s.ID)
+		}
+		if err := n.repo.Delete(sent); err != nil {
+			log.Printf("restock %d: clear subscriptions: %v", variantID, err)
+			return
+		}
+
+		afterID = subs[len(subs)-1].ID
+		if len(subs) < batchSize {
+			return
+		}
+		time.Sleep(batchPause)
+	}
+}
@@ -0,0 +1,117 @@
+package restock
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	restockDomain "ecommerce/internal/domain/restock"
+)
+
+var (
+	ErrVariantNotFound      = errors.New("variant not found")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+)
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// VariantInfo is what the notification email shows
+type VariantInfo struct {
+	ProductID int64
+	Product   string
+	Size      string
+	Color     string
+	StockQty  int
+}
+
+func (r *Repo) Variant(variantID int64) (VariantInfo, error) {
+	var v VariantInfo
+	res := r.db.Table("product_variants v").
+		Select("p.id AS product_id, p.name AS product, v.size, v.color, v.stock_qty").
+		Joins("JOIN products p ON p.id = v.product_id").
+		Where("v.id = ?", variantID).
+		Scan(&v)
+	if res.Error != nil {
+		return v, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return v, ErrVariantNotFound
+	}
+	return v, nil
+}
+
+// Subscribe stores a subscription, confirmed already when confirmed is
+// set; subscribing twice is a no-op. created reports a new row. Guest
+// subscriptions left unconfirmed for longer than confirmTTL are dropped
+// first, so the address can be asked again.
+func (r *Repo) Subscribe(variantID int64, userID *int64, email string, confirmed bool, confirmTTL time.Duration) (restockDomain.Subscription, bool, error) {
+	err := r.db.Where("confirmed_at IS NULL AND created_at < ?", time.Now().Add(-confirmTTL)).
+		Delete(&restockDomain.Subscription{}).Error
+	if err != nil {
+		return restockDomain.Subscription{}, false, err
+	}
+
+	s := restockDomain.Subscription{VariantID: variantID, UserID: userID, Email: email}
+	onConflict := clause.OnConflict{DoNothing: true}
+	if confirmed {
+		now := time.Now()
+		s.ConfirmedAt = &now
+		// the account owner takes over an unconfirmed guest row for the address
+		onConflict = clause.OnConflict{
+			Columns:   []clause.Column{{Name: "variant_id"}, {Name: "email"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"user_id": userID, "confirmed_at": now}),
+		}
+	}
+	res := r.db.Clauses(onConflict).Create(&s)
+	return s, res.RowsAffected == 1, res.Error
+}
+
+// Confirm marks a guest subscription as confirmed
+func (r *Repo) Confirm(id int64) error {
+	res := r.db.Model(&restockDomain.Subscription{}).
+		Where("id = ? AND confirmed_at IS NULL", id).
+		Update("confirmed_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var n int64
+		if err := r.db.Model(&restockDomain.Subscription{}).Where("id = ?", id).Count(&n).Error; err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrSubscriptionNotFound
+		}
+	}
+	return nil
+}
+
+func (r *Repo) EmailOf(userID int64) (string, error) {
+	var email string
+	err := r.db.Table("users").Select("email").Where("id = ?", userID).Row().Scan(&email)
+	return email, err
+}
+
+// Batch returns up to limit confirmed subscriptions for the variant with
+// id > afterID
+func (r *Repo) Batch(variantID, afterID int64, limit int) ([]restockDomain.Subscription, error) {
+	var out []restockDomain.Subscription
+	err := r.db.Where("variant_id = ? AND id > ? AND confirmed_at IS NOT NULL", variantID, afterID).
+		Order("id ASC").Limit(limit).Find(&out).Error
+	return out, err
+}
+
+func (r *Repo) Delete(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&restockDomain.Subscription{}).Error
+}
@@ -0,0 +1,92 @@
+package shipments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownTracking = errors.New("carrier does not know this tracking number")
+	ErrNoTrackingAPI   = errors.New("no carrier integration or tracking number for this shipment")
+)
+
+// TrackingEvent is one scan reported by a carrier. Adapters map their own
+// codes to the shipment.Status* values.
+type TrackingEvent struct {
+	Status      string
+	Description string
+	Location    string
+	OccurredAt  time.Time
+}
+
+// Carrier is implemented once per shipping company. Adapters are registered
+// by name; shipments whose carrier has no adapter (or whose adapter returns
+// ErrNoTrackingAPI) are tracked by hand.
+type Carrier interface {
+	Name() string
+	// TrackingURL is the public page customers can follow
+	TrackingURL(trackingNumber string) string
+	// Track returns the full scan history, oldest first
+	Track(ctx context.Context, trackingNumber string) ([]TrackingEvent, error)
+}
+
+// Carriers maps a carrier name (as stored on shipments) to its adapter
+type Carriers map[string]Carrier
+
+// NewCarriers builds the registry from adapters; names are case-insensitive
+func NewCarriers(cs ...Carrier) Carriers {
+	out := Carriers{}
+	for _, c := range cs {
+		out[strings.ToLower(c.Name())] = c
+	}
+	return out
+}
+
+func (cs Carriers) get(carrier string) (Carrier, bool) {
+	c, ok := cs[strings.ToLower(strings.TrimSpace(carrier))]
+	return c, ok
+}
+
+// TrackingURL returns "" when the carrier has no adapter
+func (cs Carriers) TrackingURL(carrier, trackingNumber string) string {
+	c, ok := cs.get(carrier)
+	if !ok || trackingNumber == "" {
+		return ""
+	}
+	return c.TrackingURL(trackingNumber)
+}
+
+// LinkCarrier knows only the carrier's public tracking page. Its shipments
+// get a tracking link, and staff add the events by hand.
+type LinkCarrier struct {
+	name      string
+	urlFormat string // %s is replaced by the tracking number
+}
+
+func NewLinkCarrier(name, urlFormat string) LinkCarrier {
+	return LinkCarrier{name: name, urlFormat: urlFormat}
+}
+
+func (l LinkCarrier) Name() string { return l.name }
+
+func (l LinkCarrier) TrackingURL(trackingNumber string) string {
+	return fmt.Sprintf(l.urlFormat, url.QueryEscape(trackingNumber))
+}
+
+func (l LinkCarrier) Track(context.Context, string) ([]TrackingEvent, error) {
+	return nil, ErrNoTrackingAPI
+}
+
+// DefaultCarriers are the carriers known out of the box (tracking links only)
+func DefaultCarriers() []Carrier {
+	return []Carrier{
+		NewLinkCarrier("ups", "https://www.ups.com/track?tracknum=%s"),
+		NewLinkCarrier("fedex", "https://www.fedex.com/fedextrack/?trknbr=%s"),
+		NewLinkCarrier("usps", "https://tools.usps.com/go/TrackConfirmAction?tLabels=%s"),
+		NewLinkCarrier("dhl", "https://www.dhl.com/global-en/home/tracking/tracking-express.html?submit=1&tracking-id=%s"),
+	}
+}
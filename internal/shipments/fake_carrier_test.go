@@ -0,0 +1,47 @@
+package shipments
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// fakeCarrier is an in-memory carrier: scans are pushed by the test and
+// returned by Track.
+type fakeCarrier struct {
+	name string
+
+	mu     sync.Mutex
+	events map[string][]TrackingEvent
+	err    error
+}
+
+func newFakeCarrier(name string) *fakeCarrier {
+	return &fakeCarrier{name: name, events: map[string][]TrackingEvent{}}
+}
+
+func (f *fakeCarrier) Name() string { return f.name }
+
+func (f *fakeCarrier) TrackingURL(trackingNumber string) string {
+	return "https://fake.example/track/" + url.PathEscape(trackingNumber)
+}
+
+// push records a scan for the tracking number
+func (f *fakeCarrier) push(trackingNumber string, ev TrackingEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[trackingNumber] = append(f.events[trackingNumber], ev)
+}
+
+func (f *fakeCarrier) Track(_ context.Context, trackingNumber string) ([]TrackingEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	evs, ok := f.events[trackingNumber]
+	if !ok {
+		return nil, ErrUnknownTracking
+	}
+	return append([]TrackingEvent(nil), evs...), nil
+}
@@ -0,0 +1,264 @@
+package shipments
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/audit"
+	"ecommerce/internal/auth"
+	shipmentDomain "ecommerce/internal/domain/shipment"
+)
+
+type Handler struct {
+	repo     *Repo
+	carriers Carriers
+	tracker  *tracker
+}
+
+func NewHandler(repo *Repo, carriers Carriers, notifier *Notifier) *Handler {
+	t := &tracker{store: repo, carriers: carriers}
+	if notifier != nil {
+		t.notifier = notifier
+	}
+	return &Handler{repo: repo, carriers: carriers, tracker: t}
+}
+
+type ItemReq struct {
+	VariantID int64 `json:"variant_id" binding:"required"`
+	Qty       int   `json:"qty" binding:"required"`
+}
+
+type CreateReq struct {
+	UserID         int64     `json:"user_id" binding:"required"`
+	AddressID      *int64    `json:"address_id"`
+	OrderReference string    `json:"order_reference"`
+	Carrier        string    `json:"carrier"`
+	TrackingNumber string    `json:"tracking_number"`
+	Items          []ItemReq `json:"items" binding:"required"`
+}
+
+type TrackingReq struct {
+	Carrier        string `json:"carrier" binding:"required"`
+	TrackingNumber string `json:"tracking_number" binding:"required"`
+}
+
+type EventReq struct {
+	Status      string     `json:"status" binding:"required"`
+	Description string     `json:"description"`
+	Location    string     `json:"location"`
+	OccurredAt  *time.Time `json:"occurred_at"` // default now
+}
+
+// MyShipments lists the current user's shipments with tracking
+func (h *Handler) MyShipments(c *gin.Context) {
+	items, err := h.repo.ListByUser(c.GetInt64(auth.CtxUserIDKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list shipments"})
+		return
+	}
+	for i := range items {
+		h.withTrackingURL(&items[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) MyShipment(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	s, err := h.repo.ForUser(c.GetInt64(auth.CtxUserIDKey), id)
+	if err != nil {
+		respondError(c, err, "failed to load shipment")
+		return
+	}
+	h.withTrackingURL(&s)
+	c.JSON(http.StatusOK, s)
+}
+
+// AdminList lists shipments (?user_id=&status=&order_reference=&page=&page_size=)
+func (h *Handler) AdminList(c *gin.Context) {
+	page, pageSize := audit.Pagination(c)
+	userID, _ := strconv.ParseInt(c.Query("user_id"), 10, 64)
+	items, total, err := h.repo.List(ListFilter{
+		UserID:         userID,
+		Status:         c.Query("status"),
+		OrderReference: strings.TrimSpace(c.Query("order_reference")),
+		Limit:          pageSize,
+		Offset:         (page - 1) * pageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list shipments"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total, "page": page, "page_size": pageSize})
+}
+
+func (h *Handler) AdminGet(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	s, err := h.repo.ByID(id)
+	if err != nil {
+		respondError(c, err, "failed to load shipment")
+		return
+	}
+	h.withTrackingURL(&s)
+	c.JSON(http.StatusOK, s)
+}
+
+// AdminCreate records a shipment. An order can be split over several
+// shipments sharing its order_reference. Giving a tracking number means
+// the parcel was handed over, so it starts as shipped.
+func (h *Handler) AdminCreate(c *gin.Context) {
+	var req CreateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a shipment needs at least one item"})
+		return
+	}
+
+	actorID := c.GetInt64(auth.CtxUserIDKey)
+	s := shipmentDomain.Shipment{
+		UserID:         req.UserID,
+		OrderReference: strings.TrimSpace(req.OrderReference),
+		Carrier:        strings.TrimSpace(req.Carrier),
+		Status:         shipmentDomain.StatusPending,
+		CreatedBy:      &actorID,
+	}
+	if tn := strings.TrimSpace(req.TrackingNumber); tn != "" {
+		if s.Carrier == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "carrier is required with a tracking number"})
+			return
+		}
+		s.TrackingNumber = &tn
+	}
+
+	// the same variant twice in one request is one line
+	index := map[int64]int{}
+	for _, it := range req.Items {
+		if it.Qty <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "qty must be positive"})
+			return
+		}
+		if i, ok := index[it.VariantID]; ok {
+			s.Items[i].Qty += it.Qty
+			continue
+		}
+		index[it.VariantID] = len(s.Items)
+		s.Items = append(s.Items, shipmentDomain.Item{VariantID: it.VariantID, Qty: it.Qty})
+	}
+
+	if err := h.repo.Create(&s, req.AddressID); err != nil {
+		respondError(c, err, "failed to create shipment")
+		return
+	}
+	// the shipment exists either way; staff can add the event by hand
+	if s.TrackingNumber != nil {
+		err := h.tracker.record(s.ID, []shipmentDomain.Event{{
+			Status: shipmentDomain.StatusShipped, Description: "Handed over to " + s.Carrier, OccurredAt: time.Now(),
+		}})
+		if err != nil {
+			log.Printf("shipment %d: shipped event: %v", s.ID, err)
+		}
+	}
+
+	h.respondShipment(c, http.StatusCreated, s.ID)
+}
+
+// AdminSetTracking sets or corrects the carrier and tracking number
+func (h *Handler) AdminSetTracking(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var req TrackingReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	tn := strings.TrimSpace(req.TrackingNumber)
+	if err := h.repo.SetTracking(id, strings.TrimSpace(req.Carrier), &tn); err != nil {
+		respondError(c, err, "failed to update tracking")
+		return
+	}
+	h.respondShipment(c, http.StatusOK, id)
+}
+
+// AdminAddEvent records a status update entered by staff
+func (h *Handler) AdminAddEvent(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var req EventReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if !shipmentDomain.ValidStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown status"})
+		return
+	}
+	ev := shipmentDomain.Event{
+		Status:      req.Status,
+		Description: strings.TrimSpace(req.Description),
+		Location:    strings.TrimSpace(req.Location),
+		OccurredAt:  time.Now(),
+	}
+	if req.OccurredAt != nil {
+		ev.OccurredAt = *req.OccurredAt
+	}
+
+	if err := h.tracker.record(id, []shipmentDomain.Event{ev}); err != nil {
+		respondError(c, err, "failed to add event")
+		return
+	}
+	h.respondShipment(c, http.StatusOK, id)
+}
+
+// AdminSync pulls the tracking history from the carrier's adapter
+func (h *Handler) AdminSync(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.tracker.sync(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, ErrNoTrackingAPI):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrUnknownTracking):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrCarrierUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": ErrCarrierUnavailable.Error()})
+		default:
+			respondError(c, err, "failed to store tracking events")
+		}
+		return
+	}
+	h.respondShipment(c, http.StatusOK, id)
+}
+
+// respondShipment reloads the shipment after a change and sends it
+func (h *Handler) respondShipment(c *gin.Context, status int, id int64) {
+	s, err := h.repo.ByID(id)
+	if err != nil {
+		respondError(c, err, "failed to load shipment")
+		return
+	}
+	h.withTrackingURL(&s)
+	c.JSON(status, s)
+}
+
+func (h *Handler) withTrackingURL(s *shipmentDomain.Shipment) {
+	if s.TrackingNumber != nil {
+		s.TrackingURL = h.carriers.TrackingURL(s.Carrier, *s.TrackingNumber)
+	}
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrUserNotFound),
+		errors.Is(err, ErrAddressNotFound), errors.Is(err, ErrVariantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTrackingTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
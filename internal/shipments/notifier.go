@@ -0,0 +1,84 @@
+package shipments
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	shipmentDomain "ecommerce/internal/domain/shipment"
+	"ecommerce/internal/mail"
+)
+
+var statusText = map[string]string{
+	shipmentDomain.StatusShipped:        "has shipped",
+	shipmentDomain.StatusInTransit:      "is on its way",
+	shipmentDomain.StatusOutForDelivery: "is out for delivery",
+	shipmentDomain.StatusDelivered:      "has been delivered",
+	shipmentDomain.StatusException:      "is delayed",
+	shipmentDomain.StatusReturned:       "is being returned to us",
+}
+
+// Notifier emails customers when a shipment changes status
+type Notifier struct {
+	repo     *Repo
+	mailer   mail.Mailer
+	carriers Carriers
+	baseURL  string
+}
+
+func NewNotifier(repo *Repo, mailer mail.Mailer, carriers Carriers, baseURL string) *Notifier {
+	return &Notifier{repo: repo, mailer: mailer, carriers: carriers, baseURL: baseURL}
+}
+
+// StatusChanged sends the update in the background; failures are logged
+func (n *Notifier) StatusChanged(shipmentID int64) {
+	go func() {
+		if err := n.notify(shipmentID); err != nil {
+			log.Printf("shipment %d: status email: %v", shipmentID, err)
+		}
+	}()
+}
+
+func (n *Notifier) notify(shipmentID int64) error {
+	s, err := n.repo.ByID(shipmentID)
+	if err != nil {
+		return err
+	}
+	text, ok := statusText[s.Status]
+	if !ok {
+		return nil // nothing worth an email (pending)
+	}
+	to, err := n.repo.Recipient(s.UserID)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Your shipment %s.\n\n", text)
+	if s.OrderReference != "" {
+		fmt.Fprintf(&b, "Order: %s\n", s.OrderReference)
+	}
+	if s.TrackingNumber != nil {
+		fmt.Fprintf(&b, "Carrier: %s\nTracking number: %s\n", s.Carrier, *s.TrackingNumber)
+		if u := n.carriers.TrackingURL(s.Carrier, *s.TrackingNumber); u != "" {
+			fmt.Fprintf(&b, "Track it: %s\n", u)
+		}
+	}
+	if len(s.Events) > 0 {
+		last := s.Events[len(s.Events)-1]
+		if last.Description != "" {
+			fmt.Fprintf(&b, "\n%s", last.Description)
+			if last.Location != "" {
+				fmt.Fprintf(&b, " (%s)", last.Location)
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\nItems in this shipment:\n")
+	for _, it := range s.Items {
+		fmt.Fprintf(&b, "  %d x %s (size %s, %s)\n", it.Qty, it.Product, it.Size, it.Color)
+	}
+	fmt.Fprintf(&b, "\nSee all your shipments: %s/account/shipments\n", n.baseURL)
+
+	return n.mailer.Send(to, "Your shipment "+text, b.String())
+}
@@ -0,0 +1,226 @@
+package shipments
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"ecommerce/internal/db"
+	shipmentDomain "ecommerce/internal/domain/shipment"
+)
+
+var (
+	ErrNotFound        = errors.New("shipment not found")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrAddressNotFound = errors.New("address not found")
+	ErrVariantNotFound = errors.New("variant not found")
+	ErrTrackingTaken   = errors.New("tracking number is already used by another shipment")
+)
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// Create inserts the shipment with its items, copying the user's address
+// addressID (if given) onto it. Items are checked to exist but not against
+// what was bought, since there are no orders yet.
+func (r *Repo) Create(s *shipmentDomain.Shipment, addressID *int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := exists(tx, "users", "id = ? AND deleted_at IS NULL", ErrUserNotFound, s.UserID); err != nil {
+			return err
+		}
+		if addressID != nil {
+			err := tx.Table("addresses").Where("id = ? AND user_id = ?", *addressID, s.UserID).Take(&s.ShipTo).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAddressNotFound
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, it := range s.Items {
+			if err := exists(tx, "product_variants", "id = ?", ErrVariantNotFound, it.VariantID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("Items", "Events").Create(s).Error; err != nil {
+			if db.IsUniqueViolation(err) {
+				return ErrTrackingTaken
+			}
+			return err
+		}
+		for i := range s.Items {
+			s.Items[i].ShipmentID = s.ID
+		}
+		return tx.Create(&s.Items).Error
+	})
+}
+
+func (r *Repo) ByID(id int64) (shipmentDomain.Shipment, error) {
+	var s shipmentDomain.Shipment
+	err := r.db.Preload("Events", orderEvents).First(&s, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s, ErrNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+	return s, r.loadItems(&s)
+}
+
+// ForUser loads one of the user's shipments
+func (r *Repo) ForUser(userID, id int64) (shipmentDomain.Shipment, error) {
+	s, err := r.ByID(id)
+	if err == nil && s.UserID != userID {
+		return shipmentDomain.Shipment{}, ErrNotFound
+	}
+	return s, err
+}
+
+// ListByUser returns the user's shipments, newest first
+func (r *Repo) ListByUser(userID int64) ([]shipmentDomain.Shipment, error) {
+	var out []shipmentDomain.Shipment
+	err := r.db.Preload("Events", orderEvents).Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		if err := r.loadItems(&out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+type ListFilter struct {
+	UserID         int64
+	Status         string
+	OrderReference string
+	Limit, Offset  int
+}
+
+// List is the staff view (no items or events)
+func (r *Repo) List(f ListFilter) ([]shipmentDomain.Shipment, int64, error) {
+	q := r.db.Model(&shipmentDomain.Shipment{})
+	if f.UserID != 0 {
+		q = q.Where("user_id = ?", f.UserID)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.OrderReference != "" {
+		q = q.Where("order_reference = ?", f.OrderReference)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []shipmentDomain.Shipment
+	err := q.Order("created_at DESC").Limit(f.Limit).Offset(f.Offset).Find(&out).Error
+	return out, total, err
+}
+
+// SetTracking sets the carrier and tracking number
+func (r *Repo) SetTracking(id int64, carrier string, trackingNumber *string) error {
+	res := r.db.Model(&shipmentDomain.Shipment{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"carrier": carrier, "tracking_number": trackingNumber})
+	if db.IsUniqueViolation(res.Error) {
+		return ErrTrackingTaken
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AddEvents stores new tracking events (already known ones are skipped) and
+// moves the shipment to the status of its latest event (see progress). It
+// returns the status before and after.
+func (r *Repo) AddEvents(id int64, events []shipmentDomain.Event) (before, after string, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var s shipmentDomain.Shipment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&s, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		var known []shipmentDomain.Event
+		if err := orderEvents(tx.Where("shipment_id = ?", id)).Find(&known).Error; err != nil {
+			return err
+		}
+
+		fresh, next := progress(s, known, events)
+		before, after = s.Status, next.Status
+		for _, ev := range fresh {
+			ev.ID, ev.ShipmentID = 0, id
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ev).Error; err != nil {
+				return err
+			}
+		}
+		if after == before {
+			return nil
+		}
+		return tx.Model(&s).Updates(map[string]interface{}{
+			"status":       next.Status,
+			"shipped_at":   next.ShippedAt,
+			"delivered_at": next.DeliveredAt,
+		}).Error
+	})
+	return before, after, err
+}
+
+// Recipient returns the email address shipment updates go to
+func (r *Repo) Recipient(userID int64) (string, error) {
+	var email string
+	err := r.db.Table("users").Select("email").
+		Where("id = ? AND deleted_at IS NULL", userID).Row().Scan(&email)
+	return email, err
+}
+
+func (r *Repo) loadItems(s *shipmentDomain.Shipment) error {
+	s.Items = []shipmentDomain.Item{}
+	rows, err := r.db.Table("shipment_items si").
+		Select("si.id, si.variant_id, si.qty, p.id, p.name, v.size, v.color").
+		Joins("JOIN product_variants v ON v.id = si.variant_id").
+		Joins("JOIN products p ON p.id = v.product_id").
+		Where("si.shipment_id = ?", s.ID).Order("si.id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		it := shipmentDomain.Item{ShipmentID: s.ID}
+		if err := rows.Scan(&it.ID, &it.VariantID, &it.Qty, &it.ProductID, &it.Product, &it.Size, &it.Color); err != nil {
+			return err
+		}
+		s.Items = append(s.Items, it)
+	}
+	return rows.Err()
+}
+
+func orderEvents(db *gorm.DB) *gorm.DB {
+	return db.Order("occurred_at ASC, id ASC")
+}
+
+func exists(tx *gorm.DB, table, where string, notFound error, args ...interface{}) error {
+	var n int64
+	if err := tx.Table(table).Where(where, args...).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package shipments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	shipmentDomain "ecommerce/internal/domain/shipment"
+)
+
+var ErrCarrierUnavailable = errors.New("carrier unavailable")
+
+// eventStore is the part of Repo the tracker needs
+type eventStore interface {
+	ByID(id int64) (shipmentDomain.Shipment, error)
+	AddEvents(id int64, events []shipmentDomain.Event) (before, after string, err error)
+}
+
+// statusNotifier is told when a shipment's status changes
+type statusNotifier interface {
+	StatusChanged(shipmentID int64)
+}
+
+// tracker records tracking events, entered by staff or pulled from a
+// carrier adapter, and notifies the customer when the status changes.
+type tracker struct {
+	store    eventStore
+	carriers Carriers
+	notifier statusNotifier
+}
+
+// record stores events and notifies the customer if the status changed
+func (t *tracker) record(id int64, events []shipmentDomain.Event) error {
+	before, after, err := t.store.AddEvents(id, events)
+	if err != nil {
+		return err
+	}
+	if after != before && t.notifier != nil {
+		t.notifier.StatusChanged(id)
+	}
+	return nil
+}
+
+// sync pulls the scan history from the shipment's carrier adapter. Scans
+// already recorded are skipped, so syncing again is harmless.
+func (t *tracker) sync(ctx context.Context, id int64) error {
+	s, err := t.store.ByID(id)
+	if err != nil {
+		return err
+	}
+	carrier, ok := t.carriers.get(s.Carrier)
+	if !ok || s.TrackingNumber == nil {
+		return ErrNoTrackingAPI
+	}
+
+	scans, err := carrier.Track(ctx, *s.TrackingNumber)
+	if errors.Is(err, ErrUnknownTracking) || errors.Is(err, ErrNoTrackingAPI) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCarrierUnavailable, err)
+	}
+
+	events := make([]shipmentDomain.Event, 0, len(scans))
+	for _, sc := range scans {
+		if !shipmentDomain.ValidStatus(sc.Status) {
+			continue
+		}
+		events = append(events, shipmentDomain.Event{
+			Status: sc.Status, Description: sc.Description, Location: sc.Location, OccurredAt: sc.OccurredAt,
+		})
+	}
+	return t.record(id, events)
+}
+
+// progress merges incoming events into a shipment's history (known, oldest
+// first). It returns the events not recorded yet and the shipment moved to
+// the status of its latest event:
+//   - the same status at the same time is the same scan (the table's unique
+//     key), so repeated syncs add nothing
+//   - shipped_at is set once, to the earliest non-pending event
+//   - delivered_at is the time of the delivered event
+func progress(s shipmentDomain.Shipment, known, incoming []shipmentDomain.Event) ([]shipmentDomain.Event, shipmentDomain.Shipment) {
+	type key struct {
+		status string
+		at     time.Time
+	}
+	keyOf := func(ev shipmentDomain.Event) key {
+		// timestamptz keeps microseconds
+		return key{ev.Status, ev.OccurredAt.UTC().Truncate(time.Microsecond)}
+	}
+
+	seen := map[key]bool{}
+	for _, ev := range known {
+		seen[keyOf(ev)] = true
+	}
+	fresh := []shipmentDomain.Event{}
+	for _, ev := range incoming {
+		if k := keyOf(ev); !seen[k] {
+			seen[k] = true
+			fresh = append(fresh, ev)
+		}
+	}
+
+	all := append(append([]shipmentDomain.Event(nil), known...), fresh...)
+	if len(all) == 0 {
+		return fresh, s
+	}
+	// latest by time; on a tie the one recorded last wins
+	latest := all[0]
+	for _, ev := range all[1:] {
+		if !ev.OccurredAt.Before(latest.OccurredAt) {
+			latest = ev
+		}
+	}
+	if latest.Status == s.Status {
+		return fresh, s
+	}
+
+	s.Status = latest.Status
+	if s.ShippedAt == nil && s.Status != shipmentDomain.StatusPending {
+		shipped := latest.OccurredAt
+		for _, ev := range all {
+			if ev.Status != shipmentDomain.StatusPending && ev.OccurredAt.Before(shipped) {
+				shipped = ev.OccurredAt
+			}
+		}
+		s.ShippedAt = &shipped
+	}
+	if s.Status == shipmentDomain.StatusDelivered {
+		delivered := latest.OccurredAt
+		s.DeliveredAt = &delivered
+	}
+	return fresh, s
+}
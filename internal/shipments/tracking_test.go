@@ -0,0 +1,227 @@
+package shipments
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	shipmentDomain "ecommerce/internal/domain/shipment"
+)
+
+// memStore keeps shipments in memory and applies events the way Repo does
+type memStore struct {
+	shipments map[int64]shipmentDomain.Shipment
+}
+
+func (m *memStore) ByID(id int64) (shipmentDomain.Shipment, error) {
+	s, ok := m.shipments[id]
+	if !ok {
+		return s, ErrNotFound
+	}
+	return s, nil
+}
+
+func (m *memStore) AddEvents(id int64, events []shipmentDomain.Event) (string, string, error) {
+	s, ok := m.shipments[id]
+	if !ok {
+		return "", "", ErrNotFound
+	}
+	fresh, next := progress(s, s.Events, events)
+	next.Events = append(append([]shipmentDomain.Event(nil), s.Events...), fresh...)
+	m.shipments[id] = next
+	return s.Status, next.Status, nil
+}
+
+type notifications struct{ ids []int64 }
+
+func (n *notifications) StatusChanged(id int64) { n.ids = append(n.ids, id) }
+
+func newTestTracker(t *testing.T) (*tracker, *memStore, *fakeCarrier, *notifications) {
+	t.Helper()
+	tn := "TRK1"
+	store := &memStore{shipments: map[int64]shipmentDomain.Shipment{
+		1: {ID: 1, Carrier: "Fake", TrackingNumber: &tn, Status: shipmentDomain.StatusPending},
+		2: {ID: 2, Carrier: "ups", TrackingNumber: &tn, Status: shipmentDomain.StatusPending},
+		3: {ID: 3, Carrier: "pigeon", TrackingNumber: &tn, Status: shipmentDomain.StatusPending},
+		4: {ID: 4, Carrier: "fake", Status: shipmentDomain.StatusPending},
+	}}
+	carrier := newFakeCarrier("fake")
+	notes := &notifications{}
+	carriers := NewCarriers(append(DefaultCarriers(), carrier)...)
+	return &tracker{store: store, carriers: carriers, notifier: notes}, store, carrier, notes
+}
+
+func scan(status string, at time.Time) TrackingEvent {
+	return TrackingEvent{Status: status, Description: status, Location: "Depot", OccurredAt: at}
+}
+
+func TestSyncFollowsCarrierScans(t *testing.T) {
+	tr, store, carrier, notes := newTestTracker(t)
+	ctx := context.Background()
+	t0 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	// first sync: handed over
+	carrier.push("TRK1", scan(shipmentDomain.StatusShipped, t0))
+	if err := tr.sync(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	s := store.shipments[1]
+	if s.Status != shipmentDomain.StatusShipped || s.ShippedAt == nil || !s.ShippedAt.Equal(t0) {
+		t.Fatalf("after first scan: status=%s shipped_at=%v", s.Status, s.ShippedAt)
+	}
+	if len(notes.ids) != 1 {
+		t.Fatalf("notifications = %d, want 1", len(notes.ids))
+	}
+
+	// repeated sync with the same history adds nothing and stays quiet
+	if err := tr.sync(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(store.shipments[1].Events); n != 1 {
+		t.Errorf("events after re-sync = %d, want 1", n)
+	}
+	if len(notes.ids) != 1 {
+		t.Errorf("re-sync notified again")
+	}
+
+	// two scans in one sync: one status change, one email
+	carrier.push("TRK1", scan(shipmentDomain.StatusInTransit, t0.Add(2*time.Hour)))
+	carrier.push("TRK1", scan(shipmentDomain.StatusOutForDelivery, t0.Add(20*time.Hour)))
+	if err := tr.sync(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if s := store.shipments[1]; s.Status != shipmentDomain.StatusOutForDelivery || len(s.Events) != 3 {
+		t.Fatalf("status=%s events=%d", s.Status, len(s.Events))
+	}
+	if len(notes.ids) != 2 {
+		t.Errorf("notifications = %d, want 2", len(notes.ids))
+	}
+
+	// delivered; shipped_at keeps the first scan
+	delivered := t0.Add(24 * time.Hour)
+	carrier.push("TRK1", scan(shipmentDomain.StatusDelivered, delivered))
+	if err := tr.sync(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	s = store.shipments[1]
+	if s.Status != shipmentDomain.StatusDelivered || s.DeliveredAt == nil || !s.DeliveredAt.Equal(delivered) {
+		t.Fatalf("status=%s delivered_at=%v", s.Status, s.DeliveredAt)
+	}
+	if !s.ShippedAt.Equal(t0) {
+		t.Errorf("shipped_at moved to %v", s.ShippedAt)
+	}
+	if len(notes.ids) != 3 {
+		t.Errorf("notifications = %d, want 3", len(notes.ids))
+	}
+
+	// a late-arriving older scan is stored but doesn't change the status
+	carrier.push("TRK1", scan(shipmentDomain.StatusInTransit, t0.Add(10*time.Hour)))
+	if err := tr.sync(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if s := store.shipments[1]; s.Status != shipmentDomain.StatusDelivered || len(s.Events) != 5 {
+		t.Errorf("status=%s events=%d", s.Status, len(s.Events))
+	}
+	if len(notes.ids) != 3 {
+		t.Errorf("older scan notified")
+	}
+}
+
+func TestSyncSkipsUnknownStatuses(t *testing.T) {
+	tr, store, carrier, notes := newTestTracker(t)
+	t0 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	carrier.push("TRK1", scan("label_printed", t0))
+	if err := tr.sync(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if s := store.shipments[1]; s.Status != shipmentDomain.StatusPending || len(s.Events) != 0 {
+		t.Errorf("status=%s events=%d", s.Status, len(s.Events))
+	}
+	if len(notes.ids) != 0 {
+		t.Errorf("notified without a status change")
+	}
+}
+
+func TestSyncErrors(t *testing.T) {
+	tr, _, carrier, _ := newTestTracker(t)
+	ctx := context.Background()
+
+	if err := tr.sync(ctx, 1); !errors.Is(err, ErrUnknownTracking) {
+		t.Errorf("unknown tracking number: %v", err)
+	}
+	if err := tr.sync(ctx, 2); !errors.Is(err, ErrNoTrackingAPI) {
+		t.Errorf("link-only carrier: %v", err)
+	}
+	if err := tr.sync(ctx, 3); !errors.Is(err, ErrNoTrackingAPI) {
+		t.Errorf("carrier without adapter: %v", err)
+	}
+	if err := tr.sync(ctx, 4); !errors.Is(err, ErrNoTrackingAPI) {
+		t.Errorf("no tracking number: %v", err)
+	}
+	if err := tr.sync(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing shipment: %v", err)
+	}
+	carrier.err = errors.New("timeout")
+	if err := tr.sync(ctx, 1); !errors.Is(err, ErrCarrierUnavailable) {
+		t.Errorf("carrier down: %v", err)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	ev := func(status string, at time.Time) shipmentDomain.Event {
+		return shipmentDomain.Event{Status: status, OccurredAt: at}
+	}
+	pending := shipmentDomain.Shipment{Status: shipmentDomain.StatusPending}
+
+	t.Run("shipped_at is the earliest non-pending event", func(t *testing.T) {
+		fresh, s := progress(pending, nil, []shipmentDomain.Event{
+			ev(shipmentDomain.StatusInTransit, t0.Add(time.Hour)),
+			ev(shipmentDomain.StatusPending, t0.Add(-time.Hour)),
+			ev(shipmentDomain.StatusShipped, t0),
+		})
+		if len(fresh) != 3 || s.Status != shipmentDomain.StatusInTransit || !s.ShippedAt.Equal(t0) {
+			t.Errorf("fresh=%d status=%s shipped_at=%v", len(fresh), s.Status, s.ShippedAt)
+		}
+	})
+
+	t.Run("duplicates within one batch and across time zones", func(t *testing.T) {
+		known := []shipmentDomain.Event{ev(shipmentDomain.StatusShipped, t0)}
+		fresh, _ := progress(pending, known, []shipmentDomain.Event{
+			ev(shipmentDomain.StatusShipped, t0.In(time.FixedZone("CET", 3600))),
+			ev(shipmentDomain.StatusInTransit, t0.Add(time.Hour)),
+			ev(shipmentDomain.StatusInTransit, t0.Add(time.Hour)),
+		})
+		if len(fresh) != 1 {
+			t.Errorf("fresh = %d, want 1", len(fresh))
+		}
+	})
+
+	t.Run("tie on time goes to the event recorded last", func(t *testing.T) {
+		_, s := progress(pending, nil, []shipmentDomain.Event{
+			ev(shipmentDomain.StatusShipped, t0),
+			ev(shipmentDomain.StatusException, t0),
+		})
+		if s.Status != shipmentDomain.StatusException {
+			t.Errorf("status = %s", s.Status)
+		}
+	})
+
+	t.Run("no events keeps the shipment", func(t *testing.T) {
+		fresh, s := progress(pending, nil, nil)
+		if len(fresh) != 0 || s.Status != shipmentDomain.StatusPending || s.ShippedAt != nil {
+			t.Errorf("fresh=%d status=%s", len(fresh), s.Status)
+		}
+	})
+}
+
+func TestCarriersTrackingURL(t *testing.T) {
+	cs := NewCarriers(DefaultCarriers()...)
+	if got := cs.TrackingURL("UPS", "1Z 999"); got != "https://www.ups.com/track?tracknum=1Z+999" {
+		t.Errorf("ups url = %q", got)
+	}
+	if got := cs.TrackingURL("pigeon", "1"); got != "" {
+		t.Errorf("unknown carrier url = %q", got)
+	}
+}
@@ -0,0 +1,81 @@
+package shipping
+
+import (
+	"math"
+
+	shippingDomain "ecommerce/internal/domain/shipping"
+)
+
+// Parcel is what a quote is priced on. It has no database access so it
+// can be built from a cart, and later from an order.
+type Parcel struct {
+	WeightGrams  int
+	Value        float64 // cart value after discounts
+	FreeShipping bool    // granted by a coupon
+}
+
+// MatchZone picks the zone for a destination: a country+region area beats a
+// whole-country area, which beats the '*' catch-all. Ties go to the lower id.
+func MatchZone(zones []shippingDomain.Zone, country, region string) (shippingDomain.Zone, bool) {
+	best, bestScore := shippingDomain.Zone{}, -1
+	for _, z := range zones {
+		for _, a := range z.Areas {
+			score := -1
+			switch {
+			case a.Country == country && a.Region != "" && a.Region == region:
+				score = 2
+			case a.Country == country && a.Region == "":
+				score = 1
+			case a.Country == shippingDomain.AnyCountry:
+				score = 0
+			}
+			if score > bestScore || (score == bestScore && score >= 0 && z.ID < best.ID) {
+				best, bestScore = z, score
+			}
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// Quote prices every method for the parcel, keeping the methods' order.
+// Methods whose rate table has no bracket for the parcel are not offered.
+// A free-shipping coupon covers standard methods only; express is still
+// charged.
+func Quote(methods []shippingDomain.Method, p Parcel) []shippingDomain.Option {
+	out := []shippingDomain.Option{}
+	for _, m := range methods {
+		key := p.Value
+		if m.Basis == shippingDomain.BasisWeight {
+			key = float64(p.WeightGrams)
+		}
+		price, ok := rateFor(m.Rates, key)
+		if !ok {
+			continue
+		}
+
+		o := shippingDomain.Option{
+			MethodID: m.ID, Name: m.Name, Kind: m.Kind,
+			Price: round2(price), MinDays: m.MinDays, MaxDays: m.MaxDays,
+		}
+		if (m.FreeThreshold != nil && p.Value >= *m.FreeThreshold) ||
+			(p.FreeShipping && m.Kind == shippingDomain.KindStandard) {
+			o.Price, o.Free = 0, true
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+// rateFor returns the price of the bracket containing v
+func rateFor(rates []shippingDomain.Rate, v float64) (float64, bool) {
+	for _, r := range rates {
+		if v >= r.MinValue && (r.MaxValue == nil || v < *r.MaxValue) {
+			return r.Price, true
+		}
+	}
+	return 0, false
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
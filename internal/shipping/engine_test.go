@@ -0,0 +1,103 @@
+package shipping
+
+import (
+	"reflect"
+	"testing"
+
+	shippingDomain "ecommerce/internal/domain/shipping"
+)
+
+func TestMatchZone(t *testing.T) {
+	zones := []shippingDomain.Zone{
+		{ID: 1, Name: "World", Areas: []shippingDomain.Area{{Country: shippingDomain.AnyCountry}}},
+		{ID: 2, Name: "US", Areas: []shippingDomain.Area{{Country: "US"}}},
+		{ID: 3, Name: "US islands", Areas: []shippingDomain.Area{{Country: "US", Region: "HI"}, {Country: "US", Region: "AK"}}},
+		{ID: 4, Name: "Canada", Areas: []shippingDomain.Area{{Country: "CA"}}},
+		{ID: 5, Name: "Canada again", Areas: []shippingDomain.Area{{Country: "CA"}}},
+	}
+
+	tests := []struct {
+		name            string
+		zones           []shippingDomain.Zone
+		country, region string
+		want            int64
+		ok              bool
+	}{
+		{"region beats country", zones, "US", "HI", 3, true},
+		{"country when the region has no zone", zones, "US", "CA", 2, true},
+		{"country without a region", zones, "US", "", 2, true},
+		{"catch-all for other countries", zones, "FR", "", 1, true},
+		{"ties go to the lower id", zones, "CA", "ON", 4, true},
+		{"no zone at all", zones[1:], "FR", "", 0, false},
+		{"no zones", nil, "US", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z, ok := MatchZone(tt.zones, tt.country, tt.region)
+			if ok != tt.ok || z.ID != tt.want {
+				t.Errorf("MatchZone(%q, %q) = %d, %v; want %d, %v", tt.country, tt.region, z.ID, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	standard := shippingDomain.Method{
+		ID: 1, Name: "Standard", Kind: shippingDomain.KindStandard, Basis: shippingDomain.BasisWeight,
+		Rates: []shippingDomain.Rate{
+			{MinValue: 0, MaxValue: ptr(1000), Price: 4.9},
+			{MinValue: 1000, MaxValue: ptr(5000), Price: 8.9},
+		},
+	}
+	express := shippingDomain.Method{
+		ID: 2, Name: "Express", Kind: shippingDomain.KindExpress, Basis: shippingDomain.BasisWeight,
+		Rates: []shippingDomain.Rate{{MinValue: 0, Price: 14.9}},
+	}
+	byValue := shippingDomain.Method{
+		ID: 3, Name: "By value", Kind: shippingDomain.KindStandard, Basis: shippingDomain.BasisValue,
+		FreeThreshold: ptr(100),
+		Rates: []shippingDomain.Rate{
+			{MinValue: 0, MaxValue: ptr(50), Price: 6},
+			{MinValue: 50, Price: 3},
+		},
+	}
+	expressThreshold := express
+	expressThreshold.FreeThreshold = ptr(100)
+	methods := []shippingDomain.Method{standard, express, byValue}
+
+	opt := func(m shippingDomain.Method, price float64, free bool) shippingDomain.Option {
+		return shippingDomain.Option{MethodID: m.ID, Name: m.Name, Kind: m.Kind, Price: price, Free: free}
+	}
+
+	tests := []struct {
+		name    string
+		methods []shippingDomain.Method
+		parcel  Parcel
+		want    []shippingDomain.Option
+	}{
+		{"lowest brackets", methods, Parcel{WeightGrams: 500, Value: 20},
+			[]shippingDomain.Option{opt(standard, 4.9, false), opt(express, 14.9, false), opt(byValue, 6, false)}},
+		{"max is exclusive, min inclusive", methods, Parcel{WeightGrams: 1000, Value: 50},
+			[]shippingDomain.Option{opt(standard, 8.9, false), opt(express, 14.9, false), opt(byValue, 3, false)}},
+		{"method without a matching bracket is dropped", methods, Parcel{WeightGrams: 5000, Value: 20},
+			[]shippingDomain.Option{opt(express, 14.9, false), opt(byValue, 6, false)}},
+		{"free threshold reached", methods, Parcel{WeightGrams: 500, Value: 100},
+			[]shippingDomain.Option{opt(standard, 4.9, false), opt(express, 14.9, false), opt(byValue, 0, true)}},
+		{"just below the free threshold", methods, Parcel{WeightGrams: 500, Value: 99.99},
+			[]shippingDomain.Option{opt(standard, 4.9, false), opt(express, 14.9, false), opt(byValue, 3, false)}},
+		{"free threshold on an express method", []shippingDomain.Method{expressThreshold}, Parcel{WeightGrams: 500, Value: 100},
+			[]shippingDomain.Option{opt(expressThreshold, 0, true)}},
+		{"coupon makes standard methods free only", methods, Parcel{WeightGrams: 500, Value: 20, FreeShipping: true},
+			[]shippingDomain.Option{opt(standard, 0, true), opt(express, 14.9, false), opt(byValue, 0, true)}},
+		{"no methods", nil, Parcel{WeightGrams: 500, Value: 20}, []shippingDomain.Option{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Quote(tt.methods, tt.parcel)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Quote() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package shipping
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	shippingDomain "ecommerce/internal/domain/shipping"
+)
+
+type Handler struct {
+	repo *Repo
+}
+
+func NewHandler(repo *Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+var countryRe = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type AreaReq struct {
+	Country string `json:"country" binding:"required"`
+	Region  string `json:"region"`
+}
+
+type ZoneReq struct {
+	Name     string    `json:"name" binding:"required"`
+	IsActive *bool     `json:"is_active"`
+	Areas    []AreaReq `json:"areas" binding:"required"`
+}
+
+// toZone validates the request and builds the zone
+func (req ZoneReq) toZone() (shippingDomain.Zone, string) {
+	z := shippingDomain.Zone{
+		Name:     strings.TrimSpace(req.Name),
+		IsActive: req.IsActive == nil || *req.IsActive,
+		Areas:    []shippingDomain.Area{},
+	}
+	if z.Name == "" {
+		return z, "name is required"
+	}
+	if len(req.Areas) == 0 {
+		return z, "a zone needs at least one area"
+	}
+	seen := map[shippingDomain.Area]bool{}
+	for _, a := range req.Areas {
+		area := shippingDomain.Area{
+			Country: strings.ToUpper(strings.TrimSpace(a.Country)),
+			Region:  strings.ToUpper(strings.TrimSpace(a.Region)),
+		}
+		switch {
+		case area.Country == shippingDomain.AnyCountry && area.Region != "":
+			return z, "the '*' area cannot have a region"
+		case area.Country != shippingDomain.AnyCountry && !countryRe.MatchString(area.Country):
+			return z, "country must be an ISO 3166-1 alpha-2 code or '*'"
+		}
+		if !seen[area] {
+			seen[area] = true
+			z.Areas = append(z.Areas, area)
+		}
+	}
+	return z, ""
+}
+
+type RateReq struct {
+	MinValue float64  `json:"min_value"`
+	MaxValue *float64 `json:"max_value"`
+	Price    float64  `json:"price"`
+}
+
+type MethodReq struct {
+	ZoneID        int64     `json:"zone_id" binding:"required"`
+	Name          string    `json:"name" binding:"required"`
+	Kind          string    `json:"kind" binding:"required"`
+	Basis         string    `json:"basis" binding:"required"`
+	FreeThreshold *float64  `json:"free_threshold"`
+	MinDays       int       `json:"min_days"`
+	MaxDays       int       `json:"max_days"`
+	IsActive      *bool     `json:"is_active"`
+	SortOrder     int       `json:"sort_order"`
+	Rates         []RateReq `json:"rates" binding:"required"`
+}
+
+// toMethod validates the request and builds the method. Rate brackets
+// must not overlap, and only the highest may be open-ended.
+func (req MethodReq) toMethod() (shippingDomain.Method, string) {
+	m := shippingDomain.Method{
+		ZoneID:        req.ZoneID,
+		Name:          strings.TrimSpace(req.Name),
+		Kind:          req.Kind,
+		Basis:         req.Basis,
+		FreeThreshold: req.FreeThreshold,
+		MinDays:       req.MinDays,
+		MaxDays:       req.MaxDays,
+		IsActive:      req.IsActive == nil || *req.IsActive,
+		SortOrder:     req.SortOrder,
+	}
+	switch {
+	case m.Name == "":
+		return m, "name is required"
+	case m.Kind != shippingDomain.KindStandard && m.Kind != shippingDomain.KindExpress && m.Kind != shippingDomain.KindPickup:
+		return m, "kind must be standard, express or pickup"
+	case m.Basis != shippingDomain.BasisWeight && m.Basis != shippingDomain.BasisValue:
+		return m, "basis must be weight or value"
+	case m.FreeThreshold != nil && *m.FreeThreshold < 0:
+		return m, "free_threshold must not be negative"
+	case m.MinDays < 0 || m.MaxDays < m.MinDays:
+		return m, "delivery days must satisfy 0 <= min_days <= max_days"
+	case len(req.Rates) == 0:
+		return m, "at least one rate is required"
+	}
+
+	for _, r := range req.Rates {
+		if r.MinValue < 0 || r.Price < 0 {
+			return m, "rates must not be negative"
+		}
+		if r.MaxValue != nil && *r.MaxValue <= r.MinValue {
+			return m, "max_value must be greater than min_value"
+		}
+		m.Rates = append(m.Rates, shippingDomain.Rate{MinValue: r.MinValue, MaxValue: r.MaxValue, Price: r.Price})
+	}
+	sort.Slice(m.Rates, func(i, j int) bool { return m.Rates[i].MinValue < m.Rates[j].MinValue })
+	for i := 1; i < len(m.Rates); i++ {
+		prev := m.Rates[i-1]
+		if prev.MaxValue == nil || *prev.MaxValue > m.Rates[i].MinValue {
+			return m, "rate brackets overlap"
+		}
+	}
+	return m, ""
+}
+
+func (h *Handler) AdminListZones(c *gin.Context) {
+	items, err := h.repo.ListZones()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list zones"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) AdminGetZone(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	z, err := h.repo.ZoneByID(id)
+	if err != nil {
+		respondError(c, err, "failed to load zone")
+		return
+	}
+	c.JSON(http.StatusOK, z)
+}
+
+func (h *Handler) AdminCreateZone(c *gin.Context) {
+	var req ZoneReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	z, msg := req.toZone()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.repo.CreateZone(&z); err != nil {
+		respondError(c, err, "failed to create zone")
+		return
+	}
+	z, _ = h.repo.ZoneByID(z.ID)
+	c.JSON(http.StatusCreated, z)
+}
+
+// AdminUpdateZone replaces the zone and its areas (PUT semantics)
+func (h *Handler) AdminUpdateZone(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req ZoneReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	z, msg := req.toZone()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	z.ID = id
+	if err := h.repo.UpdateZone(&z); err != nil {
+		respondError(c, err, "failed to update zone")
+		return
+	}
+	z, _ = h.repo.ZoneByID(id)
+	c.JSON(http.StatusOK, z)
+}
+
+func (h *Handler) AdminDeleteZone(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.repo.DeleteZone(id); err != nil {
+		respondError(c, err, "failed to delete zone")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// AdminListMethods lists methods with rates (?zone_id= to filter)
+func (h *Handler) AdminListMethods(c *gin.Context) {
+	zoneID, _ := strconv.ParseInt(c.Query("zone_id"), 10, 64)
+	items, err := h.repo.ListMethods(zoneID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list methods"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) AdminGetMethod(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	m, err := h.repo.MethodByID(id)
+	if err != nil {
+		respondError(c, err, "failed to load method")
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
+
+func (h *Handler) AdminCreateMethod(c *gin.Context) {
+	var req MethodReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	m, msg := req.toMethod()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.repo.CreateMethod(&m); err != nil {
+		respondError(c, err, "failed to create method")
+		return
+	}
+	m, _ = h.repo.MethodByID(m.ID)
+	c.JSON(http.StatusCreated, m)
+}
+
+// AdminUpdateMethod replaces the method and its rate table (PUT semantics)
+func (h *Handler) AdminUpdateMethod(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req MethodReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	m, msg := req.toMethod()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	m.ID = id
+	if err := h.repo.UpdateMethod(&m); err != nil {
+		respondError(c, err, "failed to update method")
+		return
+	}
+	m, _ = h.repo.MethodByID(id)
+	c.JSON(http.StatusOK, m)
+}
+
+func (h *Handler) AdminDeleteMethod(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.repo.DeleteMethod(id); err != nil {
+		respondError(c, err, "failed to delete method")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrZoneNotFound), errors.Is(err, ErrMethodNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,202 @@
+package shipping
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	shippingDomain "ecommerce/internal/domain/shipping"
+)
+
+var (
+	ErrZoneNotFound   = errors.New("shipping zone not found")
+	ErrMethodNotFound = errors.New("shipping method not found")
+)
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) ListZones() ([]shippingDomain.Zone, error) {
+	var out []shippingDomain.Zone
+	err := r.db.Preload("Areas").Order("id").Find(&out).Error
+	return out, err
+}
+
+func (r *Repo) ZoneByID(id int64) (shippingDomain.Zone, error) {
+	var z shippingDomain.Zone
+	err := r.db.Preload("Areas").First(&z, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return z, ErrZoneNotFound
+	}
+	return z, err
+}
+
+// CreateZone inserts the zone with its areas
+func (r *Repo) CreateZone(z *shippingDomain.Zone) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		areas := z.Areas
+		if err := tx.Omit("Areas").Create(z).Error; err != nil {
+			return err
+		}
+		return setAreas(tx, z.ID, areas)
+	})
+}
+
+// UpdateZone saves the zone fields and replaces its areas
+func (r *Repo) UpdateZone(z *shippingDomain.Zone) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&shippingDomain.Zone{}).Where("id = ?", z.ID).
+			Updates(map[string]interface{}{"name": z.Name, "is_active": z.IsActive})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrZoneNotFound
+		}
+		return setAreas(tx, z.ID, z.Areas)
+	})
+}
+
+// DeleteZone removes the zone with its methods and rates
+func (r *Repo) DeleteZone(id int64) error {
+	res := r.db.Delete(&shippingDomain.Zone{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrZoneNotFound
+	}
+	return nil
+}
+
+// ListMethods returns methods with their rates (zoneID 0 = all zones)
+func (r *Repo) ListMethods(zoneID int64) ([]shippingDomain.Method, error) {
+	q := r.db.Preload("Rates", orderRates)
+	if zoneID != 0 {
+		q = q.Where("zone_id = ?", zoneID)
+	}
+	var out []shippingDomain.Method
+	err := q.Order("zone_id, sort_order, id").Find(&out).Error
+	return out, err
+}
+
+func (r *Repo) MethodByID(id int64) (shippingDomain.Method, error) {
+	var m shippingDomain.Method
+	err := r.db.Preload("Rates", orderRates).First(&m, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return m, ErrMethodNotFound
+	}
+	return m, err
+}
+
+// CreateMethod inserts the method with its rate table
+func (r *Repo) CreateMethod(m *shippingDomain.Method) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := zoneExists(tx, m.ZoneID); err != nil {
+			return err
+		}
+		rates := m.Rates
+		if err := tx.Omit("Rates").Create(m).Error; err != nil {
+			return err
+		}
+		return setRates(tx, m.ID, rates)
+	})
+}
+
+// UpdateMethod saves all method fields and replaces its rate table
+func (r *Repo) UpdateMethod(m *shippingDomain.Method) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := zoneExists(tx, m.ZoneID); err != nil {
+			return err
+		}
+		res := tx.Model(&shippingDomain.Method{}).Where("id = ?", m.ID).Select(
+			"zone_id", "name", "kind", "basis", "free_threshold",
+			"min_days", "max_days", "is_active", "sort_order",
+		).Omit("Rates").Updates(m)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrMethodNotFound
+		}
+		return setRates(tx, m.ID, m.Rates)
+	})
+}
+
+func (r *Repo) DeleteMethod(id int64) error {
+	res := r.db.Delete(&shippingDomain.Method{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrMethodNotFound
+	}
+	return nil
+}
+
+// ForDestination returns the zone serving the destination and its active
+// methods; found is false when nothing ships there.
+func (r *Repo) ForDestination(country, region string) (shippingDomain.Zone, []shippingDomain.Method, bool, error) {
+	var zones []shippingDomain.Zone
+	err := r.db.Preload("Areas", "country IN ?", []string{country, shippingDomain.AnyCountry}).
+		Where("is_active").Order("id").Find(&zones).Error
+	if err != nil {
+		return shippingDomain.Zone{}, nil, false, err
+	}
+	z, found := MatchZone(zones, country, region)
+	if !found {
+		return z, nil, false, nil
+	}
+
+	var methods []shippingDomain.Method
+	err = r.db.Preload("Rates", orderRates).
+		Where("zone_id = ? AND is_active", z.ID).
+		Order("sort_order, id").Find(&methods).Error
+	return z, methods, true, err
+}
+
+func orderRates(db *gorm.DB) *gorm.DB {
+	return db.Order("min_value")
+}
+
+func zoneExists(tx *gorm.DB, id int64) error {
+	var n int64
+	if err := tx.Model(&shippingDomain.Zone{}).Where("id = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrZoneNotFound
+	}
+	return nil
+}
+
+func setAreas(tx *gorm.DB, zoneID int64, areas []shippingDomain.Area) error {
+	if err := tx.Where("zone_id = ?", zoneID).Delete(&shippingDomain.Area{}).Error; err != nil {
+		return err
+	}
+	for _, a := range areas {
+		a.ZoneID = zoneID
+		if err := tx.Create(&a).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setRates(tx *gorm.DB, methodID int64, rates []shippingDomain.Rate) error {
+	if err := tx.Where("method_id = ?", methodID).Delete(&shippingDomain.Rate{}).Error; err != nil {
+		return err
+	}
+	for _, rt := range rates {
+		rt.ID, rt.MethodID = 0, methodID
+		if err := tx.Create(&rt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package tax
+
+import (
+	"context"
+
+	taxDomain "ecommerce/internal/domain/tax"
+)
+
+// Destination is where the goods are delivered; it decides the rates
+type Destination struct {
+	Country    string
+	Region     string
+	PostalCode string
+}
+
+// Line is one taxable amount (a cart line after discounts)
+type Line struct {
+	Ref      int64 // caller's id for the line, e.g. the variant id
+	TaxClass string
+	Amount   float64
+}
+
+type Request struct {
+	Destination      Destination
+	Lines            []Line
+	PricesIncludeTax bool // amounts are gross; tax is extracted, not added
+}
+
+type Result struct {
+	Total     float64
+	Breakdown []taxDomain.Line
+}
+
+// Calculator computes tax for a request. RateCalculator uses the local
+// tax_rates table; an external tax service can be plugged in by
+// implementing this interface.
+type Calculator interface {
+	Calculate(ctx context.Context, req Request) (Result, error)
+}
+
+// RateCalculator is the built-in Calculator backed by tax_rates
+type RateCalculator struct {
+	repo *Repo
+}
+
+func NewRateCalculator(repo *Repo) *RateCalculator {
+	return &RateCalculator{repo: repo}
+}
+
+func (rc *RateCalculator) Calculate(_ context.Context, req Request) (Result, error) {
+	rates, err := rc.repo.ForDestination(req.Destination.Country, req.Destination.Region)
+	if err != nil {
+		return Result{}, err
+	}
+	return Compute(rates, req.Lines, req.PricesIncludeTax), nil
+}
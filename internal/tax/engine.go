@@ -0,0 +1,59 @@
+package tax
+
+import (
+	"math"
+
+	taxDomain "ecommerce/internal/domain/tax"
+)
+
+// Compute applies every rate of a line's tax class to the line. With
+// inclusive set the amount already contains the tax, so the net is
+// amount / (1 + sum of rates) and each rate takes its share of the rest.
+// Breakdown entries follow the order of rates; amounts are rounded per
+// entry and the total is the sum of the rounded entries.
+func Compute(rates []taxDomain.Rate, lines []Line, inclusive bool) Result {
+	byClass := map[string][]int{}
+	for i, r := range rates {
+		byClass[r.TaxClass] = append(byClass[r.TaxClass], i)
+	}
+
+	taxable := make([]float64, len(rates))
+	amount := make([]float64, len(rates))
+	for _, l := range lines {
+		idx := byClass[l.TaxClass]
+		if len(idx) == 0 || l.Amount <= 0 {
+			continue
+		}
+		net := l.Amount
+		if inclusive {
+			sum := 0.0
+			for _, i := range idx {
+				sum += rates[i].Rate
+			}
+			net = l.Amount / (1 + sum/100)
+		}
+		for _, i := range idx {
+			taxable[i] += net
+			amount[i] += net * rates[i].Rate / 100
+		}
+	}
+
+	res := Result{Breakdown: []taxDomain.Line{}}
+	for i, r := range rates {
+		if taxable[i] == 0 {
+			continue
+		}
+		l := taxDomain.Line{
+			Name: r.Name, TaxClass: r.TaxClass, Rate: r.Rate,
+			Taxable: round2(taxable[i]), Amount: round2(amount[i]),
+		}
+		res.Breakdown = append(res.Breakdown, l)
+		res.Total += l.Amount
+	}
+	res.Total = round2(res.Total)
+	return res
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,104 @@
+package tax
+
+import (
+	"reflect"
+	"testing"
+
+	taxDomain "ecommerce/internal/domain/tax"
+)
+
+var (
+	gst     = taxDomain.Rate{Country: "CA", Name: "GST", TaxClass: "standard", Rate: 5}
+	pstBC   = taxDomain.Rate{Country: "CA", Region: "BC", Name: "PST", TaxClass: "standard", Rate: 7}
+	reduced = taxDomain.Rate{Country: "DE", Name: "MwSt reduced", TaxClass: "food", Rate: 7}
+	vat     = taxDomain.Rate{Country: "DE", Name: "MwSt", TaxClass: "standard", Rate: 19}
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name      string
+		rates     []taxDomain.Rate
+		lines     []Line
+		inclusive bool
+		want      Result
+	}{
+		{
+			name:  "exclusive single rate",
+			rates: []taxDomain.Rate{gst},
+			lines: []Line{{TaxClass: "standard", Amount: 100}},
+			want: Result{Total: 5, Breakdown: []taxDomain.Line{
+				{Name: "GST", TaxClass: "standard", Rate: 5, Taxable: 100, Amount: 5},
+			}},
+		},
+		{
+			name:  "exclusive country and region rates stack",
+			rates: []taxDomain.Rate{gst, pstBC},
+			lines: []Line{{TaxClass: "standard", Amount: 100}, {TaxClass: "standard", Amount: 50}},
+			want: Result{Total: 18, Breakdown: []taxDomain.Line{
+				{Name: "GST", TaxClass: "standard", Rate: 5, Taxable: 150, Amount: 7.5},
+				{Name: "PST", TaxClass: "standard", Rate: 7, Taxable: 150, Amount: 10.5},
+			}},
+		},
+		{
+			name:      "inclusive extracts the tax from the gross amount",
+			rates:     []taxDomain.Rate{vat},
+			lines:     []Line{{TaxClass: "standard", Amount: 119}},
+			inclusive: true,
+			want: Result{Total: 19, Breakdown: []taxDomain.Line{
+				{Name: "MwSt", TaxClass: "standard", Rate: 19, Taxable: 100, Amount: 19},
+			}},
+		},
+		{
+			name:      "inclusive stacked rates share one net amount",
+			rates:     []taxDomain.Rate{gst, pstBC},
+			lines:     []Line{{TaxClass: "standard", Amount: 112}},
+			inclusive: true,
+			want: Result{Total: 12, Breakdown: []taxDomain.Line{
+				{Name: "GST", TaxClass: "standard", Rate: 5, Taxable: 100, Amount: 5},
+				{Name: "PST", TaxClass: "standard", Rate: 7, Taxable: 100, Amount: 7},
+			}},
+		},
+		{
+			name:  "each class gets its own rates; classes without rates are untaxed",
+			rates: []taxDomain.Rate{vat, reduced},
+			lines: []Line{
+				{TaxClass: "standard", Amount: 10},
+				{TaxClass: "food", Amount: 20},
+				{TaxClass: "zero", Amount: 30},
+			},
+			want: Result{Total: 3.3, Breakdown: []taxDomain.Line{
+				{Name: "MwSt", TaxClass: "standard", Rate: 19, Taxable: 10, Amount: 1.9},
+				{Name: "MwSt reduced", TaxClass: "food", Rate: 7, Taxable: 20, Amount: 1.4},
+			}},
+		},
+		{
+			name:  "rounded per breakdown entry, total is the sum of entries",
+			rates: []taxDomain.Rate{gst, pstBC},
+			lines: []Line{{TaxClass: "standard", Amount: 0.1}},
+			want: Result{Total: 0.02, Breakdown: []taxDomain.Line{
+				{Name: "GST", TaxClass: "standard", Rate: 5, Taxable: 0.1, Amount: 0.01},
+				{Name: "PST", TaxClass: "standard", Rate: 7, Taxable: 0.1, Amount: 0.01},
+			}},
+		},
+		{
+			name:  "zero and negative amounts are ignored",
+			rates: []taxDomain.Rate{gst},
+			lines: []Line{{TaxClass: "standard", Amount: 0}, {TaxClass: "standard", Amount: -5}},
+			want:  Result{Breakdown: []taxDomain.Line{}},
+		},
+		{
+			name:  "no rates for the destination",
+			lines: []Line{{TaxClass: "standard", Amount: 100}},
+			want:  Result{Breakdown: []taxDomain.Line{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compute(tt.rates, tt.lines, tt.inclusive)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package tax
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	taxDomain "ecommerce/internal/domain/tax"
+)
+
+type Handler struct {
+	repo *Repo
+}
+
+func NewHandler(repo *Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+var (
+	countryRe = regexp.MustCompile(`^[A-Z]{2}$`)
+	classRe   = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+)
+
+// ValidClass reports whether s can be used as a tax class name
+func ValidClass(s string) bool {
+	return classRe.MatchString(s)
+}
+
+type RateReq struct {
+	Country  string  `json:"country" binding:"required"`
+	Region   string  `json:"region"`
+	TaxClass string  `json:"tax_class"`
+	Name     string  `json:"name" binding:"required"`
+	Rate     float64 `json:"rate"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// toRate validates the request and builds the rate
+func (req RateReq) toRate() (taxDomain.Rate, string) {
+	t := taxDomain.Rate{
+		Country:  strings.ToUpper(strings.TrimSpace(req.Country)),
+		Region:   strings.ToUpper(strings.TrimSpace(req.Region)),
+		TaxClass: strings.TrimSpace(req.TaxClass),
+		Name:     strings.TrimSpace(req.Name),
+		Rate:     req.Rate,
+		IsActive: req.IsActive == nil || *req.IsActive,
+	}
+	if t.TaxClass == "" {
+		t.TaxClass = taxDomain.ClassStandard
+	}
+	switch {
+	case !countryRe.MatchString(t.Country):
+		return t, "country must be an ISO 3166-1 alpha-2 code"
+	case !ValidClass(t.TaxClass):
+		return t, "tax_class must be lowercase letters, digits or _"
+	case t.Name == "":
+		return t, "name is required"
+	case t.Rate < 0 || t.Rate > 100:
+		return t, "rate must be a percent between 0 and 100"
+	}
+	return t, ""
+}
+
+// AdminList lists tax rates (?country= to filter)
+func (h *Handler) AdminList(c *gin.Context) {
+	items, err := h.repo.List(strings.ToUpper(c.Query("country")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list tax rates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) AdminGet(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	t, err := h.repo.ByID(id)
+	if err != nil {
+		respondError(c, err, "failed to load tax rate")
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *Handler) AdminCreate(c *gin.Context) {
+	var req RateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	t, msg := req.toRate()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.repo.Create(&t); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to create tax rate (may be duplicate)"})
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+// AdminUpdate replaces the rate (PUT semantics)
+func (h *Handler) AdminUpdate(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req RateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	t, msg := req.toRate()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	t.ID = id
+	if err := h.repo.Update(&t); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			respondError(c, err, "")
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to update tax rate (may be duplicate)"})
+		return
+	}
+	t, _ = h.repo.ByID(id)
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *Handler) AdminDelete(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := h.repo.Delete(id); err != nil {
+		respondError(c, err, "failed to delete tax rate")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+type ProductClassReq struct {
+	TaxClass string `json:"tax_class" binding:"required"`
+}
+
+// AdminSetProductClass changes which rates apply to a product
+func (h *Handler) AdminSetProductClass(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var req ProductClassReq
+	if err := c.ShouldBindJSON(&req); err != nil || !ValidClass(req.TaxClass) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tax_class must be lowercase letters, digits or _"})
+		return
+	}
+	if err := h.repo.SetProductClass(id, req.TaxClass); err != nil {
+		respondError(c, err, "failed to update product")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"product_id": id, "tax_class": req.TaxClass})
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,92 @@
+package tax
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	taxDomain "ecommerce/internal/domain/tax"
+)
+
+var (
+	ErrNotFound        = errors.New("tax rate not found")
+	ErrProductNotFound = errors.New("product not found")
+)
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// List returns rates (?country= to filter), by destination then class
+func (r *Repo) List(country string) ([]taxDomain.Rate, error) {
+	q := r.db.Order("country, region, tax_class, id")
+	if country != "" {
+		q = q.Where("country = ?", country)
+	}
+	var out []taxDomain.Rate
+	err := q.Find(&out).Error
+	return out, err
+}
+
+func (r *Repo) ByID(id int64) (taxDomain.Rate, error) {
+	var t taxDomain.Rate
+	err := r.db.First(&t, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return t, ErrNotFound
+	}
+	return t, err
+}
+
+func (r *Repo) Create(t *taxDomain.Rate) error {
+	return r.db.Create(t).Error
+}
+
+// Update saves all rate fields (PUT semantics)
+func (r *Repo) Update(t *taxDomain.Rate) error {
+	res := r.db.Model(&taxDomain.Rate{}).Where("id = ?", t.ID).
+		Select("country", "region", "tax_class", "name", "rate", "is_active").
+		Updates(t)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repo) Delete(id int64) error {
+	res := r.db.Delete(&taxDomain.Rate{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ForDestination returns the active rates that apply to a destination:
+// whole-country rates first, then the region's
+func (r *Repo) ForDestination(country, region string) ([]taxDomain.Rate, error) {
+	var out []taxDomain.Rate
+	err := r.db.Where("country = ? AND (region = '' OR region = ?) AND is_active", country, region).
+		Order("region, id").Find(&out).Error
+	return out, err
+}
+
+// SetProductClass changes the tax class of a product
+func (r *Repo) SetProductClass(productID int64, class string) error {
+	res := r.db.Table("products").Where("id = ?", productID).Update("tax_class", class)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
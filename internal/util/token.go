@@ -2,7 +2,11 @@ package util
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
 	"encoding/base64"
+	"encoding/hex"
+	"strings"
 )
 
 func RandomToken(nBytes int) (string, error) {
@@ -13,3 +17,19 @@ func RandomToken(nBytes int) (string, error) {
 	// URL-safe token
 	return base64.RawURLEncoding.EncodeToString(b), nil
 }
+
+// RandomCode returns n lowercase base32 characters (no ambiguous symbols)
+func RandomCode(n int) (string, error) {
+	b := make([]byte, (n*5+7)/8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	s := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+	return s[:n], nil
+}
+
+// HashToken is how tokens and codes are stored: only their SHA-256
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,339 @@
+package wishlist
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"ecommerce/internal/auth"
+	"ecommerce/internal/cart"
+	"ecommerce/internal/util"
+)
+
+type Handler struct {
+	repo  *Repo
+	carts *cart.Repo
+}
+
+func NewHandler(repo *Repo, carts *cart.Repo) *Handler {
+	return &Handler{repo: repo, carts: carts}
+}
+
+func (h *Handler) List(c *gin.Context) {
+	userID := c.GetInt64(auth.CtxUserIDKey)
+	items, err := h.repo.ListByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list wishlists"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+type CreateWishlistReq struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	var req CreateWishlistReq
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	w, err := h.repo.Create(userID, strings.TrimSpace(req.Name))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create wishlist"})
+		return
+	}
+	c.JSON(http.StatusCreated, w)
+}
+
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	w, err := h.repo.Get(userID, id)
+	if err != nil {
+		respondError(c, err, "failed to load wishlist")
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+// Delete removes a named wishlist (the saved-for-later list cannot be deleted)
+func (h *Handler) Delete(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	if err := h.repo.Delete(userID, id); err != nil {
+		respondError(c, err, "failed to delete wishlist")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+type AddItemReq struct {
+	ProductID int64  `json:"product_id"`
+	VariantID *int64 `json:"variant_id"`
+}
+
+// AddItem adds a whole product or a specific variant to a list
+func (h *Handler) AddItem(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	var req AddItemReq
+	if err := c.ShouldBindJSON(&req); err != nil || (req.ProductID == 0 && req.VariantID == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.ProductID == 0 {
+		pid, err := h.repo.ProductOfVariant(*req.VariantID)
+		if err != nil {
+			respondError(c, err, "failed to add item")
+			return
+		}
+		req.ProductID = pid
+	}
+
+	it, err := h.repo.AddItem(userID, id, req.ProductID, req.VariantID)
+	if err != nil {
+		respondError(c, err, "failed to add item")
+		return
+	}
+	c.JSON(http.StatusOK, it)
+}
+
+func (h *Handler) RemoveItem(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := paramID(c, "item_id")
+	if !ok {
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	if err := h.repo.RemoveItem(userID, id, itemID); err != nil {
+		respondError(c, err, "failed to remove item")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+type MoveToCartReq struct {
+	Qty       int    `json:"qty"`
+	VariantID *int64 `json:"variant_id"` // required for whole-product entries
+}
+
+// MoveToCart adds the entry to the user's cart (same validation as
+// POST /api/cart/items) and removes it from the list.
+func (h *Handler) MoveToCart(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := paramID(c, "item_id")
+	if !ok {
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	var req MoveToCartReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+	}
+	if req.Qty == 0 {
+		req.Qty = 1
+	}
+	if req.Qty < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	it, err := h.repo.ItemByID(userID, id, itemID)
+	if err != nil {
+		respondError(c, err, "failed to load item")
+		return
+	}
+	variantID := it.VariantID
+	if variantID == nil {
+		if req.VariantID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "choose a variant"})
+			return
+		}
+		pid, err := h.repo.ProductOfVariant(*req.VariantID)
+		if err != nil || pid != it.ProductID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "variant does not belong to this product"})
+			return
+		}
+		variantID = req.VariantID
+	}
+
+	cartID, err := h.carts.UserCartID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load cart"})
+		return
+	}
+	// both or neither, so the entry never ends up in both places
+	err = h.repo.Transaction(h.carts, func(lists *Repo, carts *cart.Repo) error {
+		if err := carts.AddItem(cartID, *variantID, req.Qty); err != nil {
+			return err
+		}
+		return lists.RemoveItem(userID, id, itemID)
+	})
+	if err != nil {
+		respondMoveError(c, err, "failed to move item to cart")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+type SaveForLaterReq struct {
+	VariantID int64 `json:"variant_id" binding:"required"`
+}
+
+// SaveForLater moves a cart line to the user's saved-for-later list
+func (h *Handler) SaveForLater(c *gin.Context) {
+	var req SaveForLaterReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	productID, err := h.repo.ProductOfVariant(req.VariantID)
+	if err != nil {
+		respondError(c, err, "failed to save item")
+		return
+	}
+	listID, err := h.repo.SavedForLaterID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save item"})
+		return
+	}
+	cartID, err := h.carts.UserCartID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load cart"})
+		return
+	}
+	err = h.repo.Transaction(h.carts, func(lists *Repo, carts *cart.Repo) error {
+		if _, err := lists.AddItem(userID, listID, productID, &req.VariantID); err != nil {
+			return err
+		}
+		return carts.RemoveItem(cartID, req.VariantID)
+	})
+	if err != nil {
+		respondMoveError(c, err, "failed to save item")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "wishlist_id": listID})
+}
+
+// Share creates (or returns the existing) public read-only token
+func (h *Handler) Share(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	w, err := h.repo.Get(userID, id)
+	if err != nil {
+		respondError(c, err, "failed to share wishlist")
+		return
+	}
+	token := ""
+	if w.ShareToken != nil {
+		token = *w.ShareToken
+	} else {
+		token, err = util.RandomToken(24)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "random failed"})
+			return
+		}
+		if err := h.repo.SetShareToken(userID, id, &token); err != nil {
+			respondError(c, err, "failed to share wishlist")
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"share_token": token, "path": "/api/shared/wishlists/" + token})
+}
+
+// Unshare revokes the public link
+func (h *Handler) Unshare(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	userID := c.GetInt64(auth.CtxUserIDKey)
+
+	if err := h.repo.SetShareToken(userID, id, nil); err != nil {
+		respondError(c, err, "failed to unshare wishlist")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// GetShared is the public read-only view of a shared list
+func (h *Handler) GetShared(c *gin.Context) {
+	w, err := h.repo.GetShared(c.Param("token"))
+	if err != nil {
+		respondError(c, err, "failed to load wishlist")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"name": w.Name, "items": w.Items, "item_count": w.ItemCount})
+}
+
+// respondMoveError reports a failed move from either side
+func respondMoveError(c *gin.Context, err error, fallback string) {
+	var stockErr *cart.StockError
+	switch {
+	case errors.As(err, &stockErr), errors.Is(err, cart.ErrVariantNotFound),
+		errors.Is(err, cart.ErrItemNotFound), errors.Is(err, cart.ErrUnavailable):
+		cart.RespondItemError(c, err, fallback)
+	default:
+		respondError(c, err, fallback)
+	}
+}
+
+func paramID(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "wishlist not found"})
+	case errors.Is(err, ErrItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+	case errors.Is(err, ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
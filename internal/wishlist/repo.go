@@ -0,0 +1,261 @@
+package wishlist
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"ecommerce/internal/cart"
+	wishlistDomain "ecommerce/internal/domain/wishlist"
+)
+
+var (
+	ErrNotFound        = errors.New("wishlist not found")
+	ErrItemNotFound    = errors.New("wishlist item not found")
+	ErrProductNotFound = errors.New("product not found")
+)
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// Transaction runs fn with list and cart repos sharing one DB transaction,
+// for moving entries between the two
+func (r *Repo) Transaction(carts *cart.Repo, fn func(lists *Repo, carts *cart.Repo) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Repo{db: tx}, carts.WithTx(tx))
+	})
+}
+
+// ListByUser returns the user's lists with item counts (no items)
+func (r *Repo) ListByUser(userID int64) ([]wishlistDomain.Wishlist, error) {
+	var out []wishlistDomain.Wishlist
+	err := r.db.Where("user_id = ?", userID).Order("kind DESC, created_at ASC").Find(&out).Error
+	if err != nil {
+		return nil, err
+	}
+
+	type count struct {
+		WishlistID int64
+		N          int
+	}
+	var counts []count
+	if err := r.db.Table("wishlist_items wi").
+		Select("wi.wishlist_id, COUNT(*) AS n").
+		Joins("JOIN wishlists w ON w.id = wi.wishlist_id").
+		Where("w.user_id = ?", userID).
+		Group("wi.wishlist_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	byID := map[int64]int{}
+	for _, c := range counts {
+		byID[c.WishlistID] = c.N
+	}
+	for i := range out {
+		out[i].ItemCount = byID[out[i].ID]
+	}
+	return out, nil
+}
+
+func (r *Repo) Create(userID int64, name string) (wishlistDomain.Wishlist, error) {
+	w := wishlistDomain.Wishlist{UserID: userID, Name: name, Kind: wishlistDomain.KindWishlist}
+	if err := r.db.Create(&w).Error; err != nil {
+		return wishlistDomain.Wishlist{}, err
+	}
+	return w, nil
+}
+
+// SavedForLaterID returns the user's saved-for-later list, creating it on first use
+func (r *Repo) SavedForLaterID(userID int64) (int64, error) {
+	w := wishlistDomain.Wishlist{UserID: userID, Name: "Saved for later", Kind: wishlistDomain.KindSavedForLater}
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&w).Error
+	if err != nil {
+		return 0, err
+	}
+	if w.ID != 0 {
+		return w.ID, nil
+	}
+	var existing wishlistDomain.Wishlist
+	if err := r.db.Where("user_id = ? AND kind = ?", userID, wishlistDomain.KindSavedForLater).
+		First(&existing).Error; err != nil {
+		return 0, err
+	}
+	return existing.ID, nil
+}
+
+// Get loads one of the user's lists with its items
+func (r *Repo) Get(userID, id int64) (wishlistDomain.Wishlist, error) {
+	var w wishlistDomain.Wishlist
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&w).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return w, ErrNotFound
+	}
+	if err != nil {
+		return w, err
+	}
+	return r.withItems(w)
+}
+
+// GetShared loads a list by its public share token
+func (r *Repo) GetShared(token string) (wishlistDomain.Wishlist, error) {
+	var w wishlistDomain.Wishlist
+	err := r.db.Where("share_token = ?", token).First(&w).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return w, ErrNotFound
+	}
+	if err != nil {
+		return w, err
+	}
+	return r.withItems(w)
+}
+
+func (r *Repo) Delete(userID, id int64) error {
+	res := r.db.Where("id = ? AND user_id = ? AND kind = ?", id, userID, wishlistDomain.KindWishlist).
+		Delete(&wishlistDomain.Wishlist{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetShareToken sets (or with nil clears) the public share token
+func (r *Repo) SetShareToken(userID, id int64, token *string) error {
+	res := r.db.Model(&wishlistDomain.Wishlist{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("share_token", token)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AddItem adds a product (variantID nil) or a specific variant; adding an
+// existing entry is a no-op.
+func (r *Repo) AddItem(userID, wishlistID int64, productID int64, variantID *int64) (wishlistDomain.Item, error) {
+	if err := r.owns(userID, wishlistID); err != nil {
+		return wishlistDomain.Item{}, err
+	}
+
+	q := r.db.Table("products p").Where("p.id = ?", productID)
+	if variantID != nil {
+		q = q.Joins("JOIN product_variants v ON v.product_id = p.id AND v.id = ?", *variantID)
+	}
+	var n int64
+	if err := q.Count(&n).Error; err != nil {
+		return wishlistDomain.Item{}, err
+	}
+	if n == 0 {
+		return wishlistDomain.Item{}, ErrProductNotFound
+	}
+
+	it := wishlistDomain.Item{WishlistID: wishlistID, ProductID: productID, VariantID: variantID}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&it).Error; err != nil {
+		return wishlistDomain.Item{}, err
+	}
+	return it, nil
+}
+
+// ItemByID returns an entry of one of the user's lists
+func (r *Repo) ItemByID(userID, wishlistID, itemID int64) (wishlistDomain.Item, error) {
+	var it wishlistDomain.Item
+	err := r.db.Table("wishlist_items wi").
+		Select("wi.*").
+		Joins("JOIN wishlists w ON w.id = wi.wishlist_id").
+		Where("wi.id = ? AND wi.wishlist_id = ? AND w.user_id = ?", itemID, wishlistID, userID).
+		Take(&it).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return it, ErrItemNotFound
+	}
+	return it, err
+}
+
+func (r *Repo) RemoveItem(userID, wishlistID, itemID int64) error {
+	if err := r.owns(userID, wishlistID); err != nil {
+		return err
+	}
+	res := r.db.Where("id = ? AND wishlist_id = ?", itemID, wishlistID).Delete(&wishlistDomain.Item{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+// ProductOfVariant returns the product a variant belongs to
+func (r *Repo) ProductOfVariant(variantID int64) (int64, error) {
+	var productID int64
+	res := r.db.Table("product_variants").Select("product_id").Where("id = ?", variantID).Scan(&productID)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, ErrProductNotFound
+	}
+	return productID, nil
+}
+
+func (r *Repo) owns(userID, wishlistID int64) error {
+	var n int64
+	if err := r.db.Model(&wishlistDomain.Wishlist{}).
+		Where("id = ? AND user_id = ?", wishlistID, userID).
+		Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repo) withItems(w wishlistDomain.Wishlist) (wishlistDomain.Wishlist, error) {
+	rows, err := r.db.Table("wishlist_items wi").
+		Select(`wi.id, wi.product_id, wi.variant_id, wi.created_at,
+		        p.name,
+		        v.size, v.color,
+		        vp.final_price,
+		        v.stock_qty,
+		        (p.is_active AND c.is_active) as available`).
+		Joins("JOIN products p ON p.id = wi.product_id").
+		Joins("JOIN categories c ON c.id = p.category_id").
+		Joins("LEFT JOIN product_variants v ON v.id = wi.variant_id").
+		Joins("LEFT JOIN variant_prices vp ON vp.variant_id = wi.variant_id").
+		Where("wi.wishlist_id = ?", w.ID).
+		Order("wi.created_at DESC").
+		Rows()
+	if err != nil {
+		return w, err
+	}
+	defer rows.Close()
+
+	w.Items = []wishlistDomain.Item{}
+	for rows.Next() {
+		it := wishlistDomain.Item{WishlistID: w.ID}
+		if err := rows.Scan(
+			&it.ID, &it.ProductID, &it.VariantID, &it.CreatedAt,
+			&it.Product,
+			&it.Size, &it.Color,
+			&it.FinalPrice,
+			&it.StockQty,
+			&it.Available,
+		); err != nil {
+			return w, err
+		}
+		w.Items = append(w.Items, it)
+	}
+	w.ItemCount = len(w.Items)
+	return w, rows.Err()
+}
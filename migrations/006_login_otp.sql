@@ -0,0 +1,5 @@
+-- Allow passwordless login codes in user_otps
+ALTER TABLE user_otps DROP CONSTRAINT IF EXISTS user_otps_purpose_check;
+ALTER TABLE user_otps
+ADD CONSTRAINT user_otps_purpose_check
+CHECK (purpose IN ('verify_email','reset_password','login'));
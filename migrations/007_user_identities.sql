@@ -0,0 +1,18 @@
+-- External (OpenID Connect) identities linked to local users
+CREATE TABLE IF NOT EXISTS user_identities (
+  id          BIGSERIAL PRIMARY KEY,
+  user_id     BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider    TEXT NOT NULL,
+  subject     TEXT NOT NULL,
+  email       CITEXT,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE(provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+
+DROP TRIGGER IF EXISTS trg_user_identities_updated_at ON user_identities;
+CREATE TRIGGER trg_user_identities_updated_at
+BEFORE UPDATE ON user_identities
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
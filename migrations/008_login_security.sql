@@ -0,0 +1,29 @@
+-- Failed login counting / lockout (per account and per IP) + known devices
+CREATE TABLE IF NOT EXISTS login_throttles (
+  key            TEXT PRIMARY KEY,            -- 'user:<id>' or 'ip:<addr>'
+  user_id        BIGINT REFERENCES users(id) ON DELETE CASCADE,
+  fail_count     INT NOT NULL DEFAULT 0,
+  lockout_count  INT NOT NULL DEFAULT 0,      -- drives exponential backoff
+  locked_until   TIMESTAMPTZ,
+  last_failed_at TIMESTAMPTZ,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_throttles_locked ON login_throttles(locked_until);
+
+DROP TRIGGER IF EXISTS trg_login_throttles_updated_at ON login_throttles;
+CREATE TRIGGER trg_login_throttles_updated_at
+BEFORE UPDATE ON login_throttles
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS user_known_devices (
+  id            BIGSERIAL PRIMARY KEY,
+  user_id       BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  fingerprint   TEXT NOT NULL,                -- sha256(ip|user agent)
+  ip            TEXT NOT NULL,
+  user_agent    TEXT NOT NULL DEFAULT '',
+  first_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_seen_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE(user_id, fingerprint)
+);
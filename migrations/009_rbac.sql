@@ -0,0 +1,72 @@
+-- Roles + permissions (replaces the fixed user/admin CHECK)
+CREATE TABLE IF NOT EXISTS roles (
+  id          BIGSERIAL PRIMARY KEY,
+  name        TEXT UNIQUE NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  is_system   BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DROP TRIGGER IF EXISTS trg_roles_updated_at ON roles;
+CREATE TRIGGER trg_roles_updated_at
+BEFORE UPDATE ON roles
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS permissions (
+  id          BIGSERIAL PRIMARY KEY,
+  code        TEXT UNIQUE NOT NULL,
+  description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role_id       BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+  permission_id BIGINT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+  PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO roles (name, description, is_system) VALUES
+  ('user',            'Shopper', TRUE),
+  ('admin',           'Full access', TRUE),
+  ('catalog_editor',  'Manages categories and products', TRUE),
+  ('inventory_clerk', 'Updates stock levels', TRUE),
+  ('support_agent',   'Looks up customer accounts', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (code, description) VALUES
+  ('admin:access',     'Access the admin area'),
+  ('categories:write', 'Create and edit categories'),
+  ('products:write',   'Create and edit products'),
+  ('inventory:write',  'Change variant stock'),
+  ('users:read',       'View user accounts'),
+  ('users:write',      'Change user accounts'),
+  ('roles:manage',     'Manage roles and role assignments'),
+  ('security:manage',  'View and lift login lockouts')
+ON CONFLICT (code) DO NOTHING;
+
+-- admin gets everything
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('admin:access','categories:write','products:write')
+WHERE r.name = 'catalog_editor'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('admin:access','inventory:write')
+WHERE r.name = 'inventory_clerk'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('admin:access','users:read','security:manage')
+WHERE r.name = 'support_agent'
+ON CONFLICT DO NOTHING;
+
+-- users.role now references roles instead of a fixed list
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users
+ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
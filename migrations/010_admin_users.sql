@@ -0,0 +1,26 @@
+-- Audit log of admin actions
+CREATE TABLE IF NOT EXISTS audit_log (
+  id          BIGSERIAL PRIMARY KEY,
+  actor_id    BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  action      TEXT NOT NULL,
+  target_type TEXT NOT NULL,
+  target_id   TEXT NOT NULL,
+  details     JSONB,
+  ip          TEXT,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id);
+
+INSERT INTO permissions (code, description) VALUES
+  ('audit:read', 'View the admin audit log')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'audit:read'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- admin user list is ordered newest first
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at DESC);
@@ -0,0 +1,11 @@
+-- Profile fields, pending email change, account deletion (anonymization)
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS display_name TEXT,
+ADD COLUMN IF NOT EXISTS phone TEXT,
+ADD COLUMN IF NOT EXISTS pending_email CITEXT,
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE user_otps DROP CONSTRAINT IF EXISTS user_otps_purpose_check;
+ALTER TABLE user_otps
+ADD CONSTRAINT user_otps_purpose_check
+CHECK (purpose IN ('verify_email','reset_password','login','change_email'));
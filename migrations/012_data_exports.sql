@@ -0,0 +1,13 @@
+-- GDPR personal data exports (archive on disk, signed download link)
+CREATE TABLE IF NOT EXISTS data_exports (
+  id           BIGSERIAL PRIMARY KEY,
+  user_id      BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status       TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','ready','failed')),
+  file_path    TEXT,
+  error        TEXT,
+  expires_at   TIMESTAMPTZ,
+  completed_at TIMESTAMPTZ,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user ON data_exports(user_id);
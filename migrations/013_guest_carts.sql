@@ -0,0 +1,9 @@
+-- Guest carts: identified by a hashed opaque token instead of a user
+ALTER TABLE carts ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE carts ADD COLUMN IF NOT EXISTS guest_token_hash TEXT UNIQUE;
+
+ALTER TABLE carts DROP CONSTRAINT IF EXISTS carts_owner_check;
+ALTER TABLE carts
+ADD CONSTRAINT carts_owner_check CHECK (user_id IS NOT NULL OR guest_token_hash IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_carts_guest_updated ON carts(updated_at) WHERE user_id IS NULL;
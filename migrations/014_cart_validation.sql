@@ -0,0 +1,7 @@
+-- Unit price when the line was added/last topped up, to flag price changes
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS added_price NUMERIC(12,2);
+
+UPDATE cart_items ci
+SET added_price = ROUND(v.price * (100 - v.discount_percent) / 100.0, 2)
+FROM product_variants v
+WHERE v.id = ci.variant_id AND ci.added_price IS NULL;
@@ -0,0 +1,31 @@
+-- Named wishlists plus one "saved for later" list per user
+CREATE TABLE IF NOT EXISTS wishlists (
+  id          BIGSERIAL PRIMARY KEY,
+  user_id     BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name        TEXT NOT NULL,
+  kind        TEXT NOT NULL DEFAULT 'wishlist' CHECK (kind IN ('wishlist','saved_for_later')),
+  share_token TEXT UNIQUE, -- public read-only link; NULL = not shared
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlists_user ON wishlists(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_wishlists_saved_for_later
+ON wishlists(user_id) WHERE kind = 'saved_for_later';
+
+DROP TRIGGER IF EXISTS trg_wishlists_updated_at ON wishlists;
+CREATE TRIGGER trg_wishlists_updated_at
+BEFORE UPDATE ON wishlists
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- An entry is a whole product or one specific variant of it
+CREATE TABLE IF NOT EXISTS wishlist_items (
+  id          BIGSERIAL PRIMARY KEY,
+  wishlist_id BIGINT NOT NULL REFERENCES wishlists(id) ON DELETE CASCADE,
+  product_id  BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  variant_id  BIGINT REFERENCES product_variants(id) ON DELETE CASCADE,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_wishlist_items_entry
+ON wishlist_items(wishlist_id, product_id, COALESCE(variant_id, 0));
@@ -0,0 +1,11 @@
+-- Back-in-stock subscriptions; rows are deleted once the email is sent
+CREATE TABLE IF NOT EXISTS stock_notifications (
+  id          BIGSERIAL PRIMARY KEY,
+  variant_id  BIGINT NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+  user_id     BIGINT REFERENCES users(id) ON DELETE CASCADE, -- NULL for guests
+  email       CITEXT NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE(variant_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_notifications_user ON stock_notifications(user_id);
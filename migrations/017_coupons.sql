@@ -0,0 +1,44 @@
+-- Coupon / promo codes
+CREATE TABLE IF NOT EXISTS coupons (
+  id             BIGSERIAL PRIMARY KEY,
+  code           CITEXT UNIQUE NOT NULL,
+  description    TEXT NOT NULL DEFAULT '',
+  kind           TEXT NOT NULL CHECK (kind IN ('percent','fixed','free_shipping')),
+  value          NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (value >= 0),
+  starts_at      TIMESTAMPTZ,
+  ends_at        TIMESTAMPTZ,
+  min_cart_value NUMERIC(12,2) NOT NULL DEFAULT 0,
+  is_active      BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (kind <> 'percent' OR value <= 100)
+);
+
+DROP TRIGGER IF EXISTS trg_coupons_updated_at ON coupons;
+CREATE TRIGGER trg_coupons_updated_at
+BEFORE UPDATE ON coupons
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Optional restrictions: when any are set, only matching lines are discounted
+CREATE TABLE IF NOT EXISTS coupon_categories (
+  coupon_id   BIGINT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+  category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  PRIMARY KEY (coupon_id, category_id)
+);
+
+CREATE TABLE IF NOT EXISTS coupon_products (
+  coupon_id  BIGINT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+  product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  PRIMARY KEY (coupon_id, product_id)
+);
+
+ALTER TABLE carts ADD COLUMN IF NOT EXISTS coupon_id BIGINT REFERENCES coupons(id) ON DELETE SET NULL;
+
+INSERT INTO permissions (code, description) VALUES
+  ('promotions:write', 'Manage coupons, sales and promotions')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'promotions:write'
+WHERE r.name IN ('admin', 'catalog_editor')
+ON CONFLICT DO NOTHING;
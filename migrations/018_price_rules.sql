@@ -0,0 +1,59 @@
+-- Scheduled sales: percentage off a category, product type, product or variant
+CREATE TABLE IF NOT EXISTS price_rules (
+  id          BIGSERIAL PRIMARY KEY,
+  name        TEXT NOT NULL,
+  target_type TEXT NOT NULL CHECK (target_type IN ('category','product_type','product','variant')),
+  target_id   BIGINT NOT NULL,
+  percent_off INT NOT NULL CHECK (percent_off > 0 AND percent_off <= 100),
+  starts_at   TIMESTAMPTZ NOT NULL,
+  ends_at     TIMESTAMPTZ,
+  is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+  created_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_rules_target ON price_rules(target_type, target_id);
+
+DROP TRIGGER IF EXISTS trg_price_rules_updated_at ON price_rules;
+CREATE TRIGGER trg_price_rules_updated_at
+BEFORE UPDATE ON price_rules
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Effective discount per variant right now. Precedence:
+--   1. only active rules whose window contains now() are considered
+--   2. the most specific target wins: variant > product > product_type > category
+--   3. among equally specific rules the larger percent_off wins, then the newest
+--   4. the winning rule only applies if it beats the variant's own discount_percent
+-- Every price shown or charged must be read through this view.
+CREATE OR REPLACE VIEW variant_prices AS
+SELECT v.id AS variant_id,
+       v.price,
+       v.discount_percent AS base_discount_percent,
+       GREATEST(v.discount_percent, COALESCE(r.percent_off, 0)) AS discount_percent,
+       ROUND(v.price * (100 - GREATEST(v.discount_percent, COALESCE(r.percent_off, 0))) / 100.0, 2) AS final_price,
+       CASE WHEN r.percent_off > v.discount_percent THEN r.id END AS price_rule_id,
+       CASE WHEN r.percent_off > v.discount_percent THEN r.ends_at END AS sale_ends_at
+FROM product_variants v
+JOIN products p ON p.id = v.product_id
+LEFT JOIN LATERAL (
+  SELECT pr.id, pr.percent_off, pr.ends_at
+  FROM price_rules pr
+  WHERE pr.is_active
+    AND pr.starts_at <= now()
+    AND (pr.ends_at IS NULL OR pr.ends_at > now())
+    AND (   (pr.target_type = 'variant'      AND pr.target_id = v.id)
+         OR (pr.target_type = 'product'      AND pr.target_id = p.id)
+         OR (pr.target_type = 'product_type' AND pr.target_id = p.type_id)
+         OR (pr.target_type = 'category'     AND pr.target_id = p.category_id))
+  ORDER BY CASE pr.target_type
+             WHEN 'variant' THEN 1
+             WHEN 'product' THEN 2
+             WHEN 'product_type' THEN 3
+             ELSE 4
+           END,
+           pr.percent_off DESC,
+           pr.id DESC
+  LIMIT 1
+) r ON TRUE;
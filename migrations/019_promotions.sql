@@ -0,0 +1,32 @@
+-- Cart-level promotions: quantity tiers ("buy 3, get 10% off") and bundles
+CREATE TABLE IF NOT EXISTS promotions (
+  id          BIGSERIAL PRIMARY KEY,
+  name        TEXT NOT NULL,
+  kind        TEXT NOT NULL CHECK (kind IN ('quantity_tier','bundle')),
+  percent_off INT NOT NULL CHECK (percent_off > 0 AND percent_off <= 100),
+  -- quantity_tier only: which items count and how many are needed
+  target_type TEXT CHECK (target_type IN ('category','product','variant')),
+  target_id   BIGINT,
+  min_qty     INT CHECK (min_qty > 0),
+  priority    INT NOT NULL DEFAULT 0, -- higher is evaluated first
+  starts_at   TIMESTAMPTZ,
+  ends_at     TIMESTAMPTZ,
+  is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (kind <> 'quantity_tier' OR (target_type IS NOT NULL AND target_id IS NOT NULL AND min_qty IS NOT NULL)),
+  CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+DROP TRIGGER IF EXISTS trg_promotions_updated_at ON promotions;
+CREATE TRIGGER trg_promotions_updated_at
+BEFORE UPDATE ON promotions
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Bundle components: one bundle needs qty units of each product
+CREATE TABLE IF NOT EXISTS promotion_bundle_items (
+  promotion_id BIGINT NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+  product_id   BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  qty          INT NOT NULL DEFAULT 1 CHECK (qty > 0),
+  PRIMARY KEY (promotion_id, product_id)
+);
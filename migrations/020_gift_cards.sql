@@ -0,0 +1,56 @@
+-- Gift cards (code stored hashed) and a store-credit wallet per user
+CREATE TABLE IF NOT EXISTS gift_cards (
+  id              BIGSERIAL PRIMARY KEY,
+  code_hash       TEXT UNIQUE NOT NULL,
+  last4           TEXT NOT NULL,
+  initial_balance NUMERIC(12,2) NOT NULL CHECK (initial_balance > 0),
+  balance         NUMERIC(12,2) NOT NULL CHECK (balance >= 0),
+  recipient_email CITEXT,
+  expires_at      TIMESTAMPTZ,
+  is_active       BOOLEAN NOT NULL DEFAULT TRUE,
+  issued_by       BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DROP TRIGGER IF EXISTS trg_gift_cards_updated_at ON gift_cards;
+CREATE TRIGGER trg_gift_cards_updated_at
+BEFORE UPDATE ON gift_cards
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS store_credit_wallets (
+  user_id    BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  balance    NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DROP TRIGGER IF EXISTS trg_store_credit_wallets_updated_at ON store_credit_wallets;
+CREATE TRIGGER trg_store_credit_wallets_updated_at
+BEFORE UPDATE ON store_credit_wallets
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Every balance change, for gift cards and wallets alike (append-only)
+CREATE TABLE IF NOT EXISTS credit_ledger (
+  id            BIGSERIAL PRIMARY KEY,
+  gift_card_id  BIGINT REFERENCES gift_cards(id) ON DELETE RESTRICT,
+  user_id       BIGINT REFERENCES users(id) ON DELETE RESTRICT,
+  amount        NUMERIC(12,2) NOT NULL CHECK (amount <> 0), -- + credit, - debit
+  balance_after NUMERIC(12,2) NOT NULL,
+  reason        TEXT NOT NULL CHECK (reason IN ('issue','adjust','refund','transfer')),
+  reference     TEXT NOT NULL DEFAULT '', -- order number, note, ...
+  actor_id      BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((gift_card_id IS NULL) <> (user_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_ledger_gift_card ON credit_ledger(gift_card_id);
+CREATE INDEX IF NOT EXISTS idx_credit_ledger_user ON credit_ledger(user_id);
+
+INSERT INTO permissions (code, description) VALUES
+  ('credit:manage', 'Issue and adjust gift cards and store credit')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'credit:manage'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,31 @@
+-- Customer address book; at most one default shipping and one default
+-- billing address per user
+CREATE TABLE IF NOT EXISTS addresses (
+  id                  BIGSERIAL PRIMARY KEY,
+  user_id             BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  label               TEXT NOT NULL DEFAULT '', -- "Home", "Office", ...
+  full_name           TEXT NOT NULL,
+  company             TEXT NOT NULL DEFAULT '',
+  line1               TEXT NOT NULL,
+  line2               TEXT NOT NULL DEFAULT '',
+  city                TEXT NOT NULL,
+  region              TEXT NOT NULL DEFAULT '', -- state / province code
+  postal_code         TEXT NOT NULL DEFAULT '',
+  country             CHAR(2) NOT NULL CHECK (country ~ '^[A-Z]{2}$'), -- ISO 3166-1 alpha-2
+  phone               TEXT NOT NULL DEFAULT '',
+  is_default_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+  is_default_billing  BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_addresses_user ON addresses(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_addresses_default_shipping
+ON addresses(user_id) WHERE is_default_shipping;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_addresses_default_billing
+ON addresses(user_id) WHERE is_default_billing;
+
+DROP TRIGGER IF EXISTS trg_addresses_updated_at ON addresses;
+CREATE TRIGGER trg_addresses_updated_at
+BEFORE UPDATE ON addresses
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,71 @@
+-- Parcel weight per variant, used by weight-based shipping rates
+ALTER TABLE product_variants
+ADD COLUMN IF NOT EXISTS weight_grams INTEGER NOT NULL DEFAULT 0 CHECK (weight_grams >= 0);
+
+-- A zone is a set of destinations sharing the same shipping methods
+CREATE TABLE IF NOT EXISTS shipping_zones (
+  id         BIGSERIAL PRIMARY KEY,
+  name       TEXT NOT NULL,
+  is_active  BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DROP TRIGGER IF EXISTS trg_shipping_zones_updated_at ON shipping_zones;
+CREATE TRIGGER trg_shipping_zones_updated_at
+BEFORE UPDATE ON shipping_zones
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- country '*' matches everywhere (rest of world); region '' matches the
+-- whole country. The most specific match wins.
+CREATE TABLE IF NOT EXISTS shipping_zone_areas (
+  zone_id BIGINT NOT NULL REFERENCES shipping_zones(id) ON DELETE CASCADE,
+  country TEXT NOT NULL CHECK (country ~ '^[A-Z]{2}$' OR country = '*'),
+  region  TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (zone_id, country, region)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipping_zone_areas_country ON shipping_zone_areas(country);
+
+CREATE TABLE IF NOT EXISTS shipping_methods (
+  id             BIGSERIAL PRIMARY KEY,
+  zone_id        BIGINT NOT NULL REFERENCES shipping_zones(id) ON DELETE CASCADE,
+  name           TEXT NOT NULL,
+  kind           TEXT NOT NULL CHECK (kind IN ('standard','express','pickup')),
+  basis          TEXT NOT NULL CHECK (basis IN ('weight','value')), -- what rates are keyed on
+  free_threshold NUMERIC(12,2) CHECK (free_threshold >= 0),         -- free from this cart value
+  min_days       INTEGER NOT NULL DEFAULT 0 CHECK (min_days >= 0),
+  max_days       INTEGER NOT NULL DEFAULT 0 CHECK (max_days >= min_days),
+  is_active      BOOLEAN NOT NULL DEFAULT TRUE,
+  sort_order     INTEGER NOT NULL DEFAULT 0,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipping_methods_zone ON shipping_methods(zone_id);
+
+DROP TRIGGER IF EXISTS trg_shipping_methods_updated_at ON shipping_methods;
+CREATE TRIGGER trg_shipping_methods_updated_at
+BEFORE UPDATE ON shipping_methods
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Rate table brackets: grams or cart value in [min_value, max_value);
+-- max_value NULL = no upper bound
+CREATE TABLE IF NOT EXISTS shipping_rates (
+  id        BIGSERIAL PRIMARY KEY,
+  method_id BIGINT NOT NULL REFERENCES shipping_methods(id) ON DELETE CASCADE,
+  min_value NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (min_value >= 0),
+  max_value NUMERIC(12,2) CHECK (max_value > min_value),
+  price     NUMERIC(12,2) NOT NULL CHECK (price >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipping_rates_method ON shipping_rates(method_id);
+
+INSERT INTO permissions (code, description) VALUES
+  ('shipping:manage', 'Manage shipping zones, methods and rates')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'shipping:manage'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
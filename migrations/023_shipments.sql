@@ -0,0 +1,68 @@
+-- Shipments and their tracking history. There is no orders table yet, so a
+-- shipment names the customer and the shipped variants directly;
+-- order_reference links it to the purchase until orders exist. Several
+-- shipments may share a reference (split shipments). The delivery address
+-- is copied in (ship_to_*) so editing or deleting the saved one later
+-- doesn't change where a parcel went.
+CREATE TABLE IF NOT EXISTS shipments (
+  id                  BIGSERIAL PRIMARY KEY,
+  user_id             BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+  order_reference     TEXT NOT NULL DEFAULT '',
+  carrier             TEXT NOT NULL DEFAULT '',
+  tracking_number     TEXT,
+  status              TEXT NOT NULL DEFAULT 'pending'
+                      CHECK (status IN ('pending','shipped','in_transit','out_for_delivery','delivered','exception','returned')),
+  shipped_at          TIMESTAMPTZ,
+  delivered_at        TIMESTAMPTZ,
+  ship_to_full_name   TEXT NOT NULL DEFAULT '',
+  ship_to_company     TEXT NOT NULL DEFAULT '',
+  ship_to_line1       TEXT NOT NULL DEFAULT '',
+  ship_to_line2       TEXT NOT NULL DEFAULT '',
+  ship_to_city        TEXT NOT NULL DEFAULT '',
+  ship_to_region      TEXT NOT NULL DEFAULT '',
+  ship_to_postal_code TEXT NOT NULL DEFAULT '',
+  ship_to_country     TEXT NOT NULL DEFAULT '',
+  ship_to_phone       TEXT NOT NULL DEFAULT '',
+  created_by          BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_user ON shipments(user_id);
+CREATE INDEX IF NOT EXISTS idx_shipments_order_reference ON shipments(order_reference) WHERE order_reference <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_shipments_tracking
+ON shipments(carrier, tracking_number) WHERE tracking_number IS NOT NULL;
+
+DROP TRIGGER IF EXISTS trg_shipments_updated_at ON shipments;
+CREATE TRIGGER trg_shipments_updated_at
+BEFORE UPDATE ON shipments
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shipment_items (
+  id          BIGSERIAL PRIMARY KEY,
+  shipment_id BIGINT NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+  variant_id  BIGINT NOT NULL REFERENCES product_variants(id) ON DELETE RESTRICT,
+  qty         INTEGER NOT NULL CHECK (qty > 0),
+  UNIQUE (shipment_id, variant_id)
+);
+
+-- Tracking history, from the carrier or entered by staff
+CREATE TABLE IF NOT EXISTS shipment_events (
+  id          BIGSERIAL PRIMARY KEY,
+  shipment_id BIGINT NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+  status      TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  location    TEXT NOT NULL DEFAULT '',
+  occurred_at TIMESTAMPTZ NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (shipment_id, occurred_at, status)
+);
+
+INSERT INTO permissions (code, description) VALUES
+  ('fulfillment:manage', 'Create shipments and update tracking')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'fulfillment:manage'
+WHERE r.name IN ('admin', 'inventory_clerk')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,33 @@
+-- Products are taxed by class; rates for a class are looked up by destination
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class TEXT NOT NULL DEFAULT 'standard';
+
+-- Every active rate matching the destination applies: whole-country rates
+-- (region '') and rates for the destination's region stack, e.g. GST + PST.
+CREATE TABLE IF NOT EXISTS tax_rates (
+  id         BIGSERIAL PRIMARY KEY,
+  country    CHAR(2) NOT NULL CHECK (country ~ '^[A-Z]{2}$'),
+  region     TEXT NOT NULL DEFAULT '',
+  tax_class  TEXT NOT NULL DEFAULT 'standard',
+  name       TEXT NOT NULL, -- shown in the breakdown, e.g. "VAT"
+  rate       NUMERIC(7,4) NOT NULL CHECK (rate >= 0 AND rate <= 100), -- percent
+  is_active  BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (country, region, tax_class, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_rates_country ON tax_rates(country);
+
+DROP TRIGGER IF EXISTS trg_tax_rates_updated_at ON tax_rates;
+CREATE TRIGGER trg_tax_rates_updated_at
+BEFORE UPDATE ON tax_rates
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+INSERT INTO permissions (code, description) VALUES
+  ('tax:manage', 'Manage tax rates and product tax classes')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'tax:manage'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
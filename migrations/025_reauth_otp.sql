@@ -0,0 +1,5 @@
+-- Re-authentication code for accounts created by social login
+ALTER TABLE user_otps DROP CONSTRAINT IF EXISTS user_otps_purpose_check;
+ALTER TABLE user_otps
+ADD CONSTRAINT user_otps_purpose_check
+CHECK (purpose IN ('verify_email','reset_password','login','change_email','reauth'));
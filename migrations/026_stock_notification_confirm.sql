@@ -0,0 +1,7 @@
+-- Guest subscriptions need the address owner to confirm (double opt-in);
+-- only confirmed rows are emailed. Existing account subscriptions count as
+-- confirmed, existing guest ones don't.
+ALTER TABLE stock_notifications ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMPTZ;
+
+UPDATE stock_notifications SET confirmed_at = created_at
+WHERE confirmed_at IS NULL AND user_id IS NOT NULL;